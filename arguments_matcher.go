@@ -1,40 +1,1438 @@
 package docopt
 
-type ArgumentsMatcher struct{}
+import (
+	"strconv"
+	"strings"
+)
+
+type ArgumentsMatcher struct {
+	Abbreviate   bool
+	OptionsFirst bool
+}
+
+type matchInput struct {
+	options []TokenOption
+	words   []string
+	end     int
+
+	optionIndexes []int
+	wordIndexes   []int
+}
+
+type matchBinding struct {
+	key   string
+	value string
+}
+
+type matchState struct {
+	input *matchInput
+
+	used   []bool
+	word   int
+	offset int
+
+	bindings []matchBinding
+
+	unique   map[string]bool
+	failures map[matchFailure]bool
+
+	// optional is count of bindings made before the innermost optional
+	// group being matched was entered, or -1 outside of optional groups.
+	// Elements of optional group are not reported as missing until the
+	// group consumes something.
+	optional int
+
+	// context holds positions where iterations of enclosing repeats have
+	// started, because they determine how iterations continue, so failures
+	// are remembered per context.
+	context string
+
+	diagnosis *matchDiagnosis
+
+	// completing makes leaves record candidates when they are reached after
+	// all words are consumed instead of failing. Candidates are pending until
+	// the path turns out to be viable, that is consumes all words and all
+	// given options.
+	completing bool
+	beyond     bool
+	pending    []Candidate
+	candidates []Candidate
+	committed  map[Candidate]bool
+	ends       int
+	viables    map[matchFailure]bool
+}
+
+// matchDiagnosis collects the furthest progress made by match attempts of
+// a single variant to explain why it has not matched.
+type matchDiagnosis struct {
+	commands map[string]bool
+	reached  int
+
+	missing string
+	path    []string
+
+	unexpected int
+	after      string
+
+	unused int
+
+	// expected is shared by all variants and holds commands which were
+	// expected at the given word.
+	expected map[int][]string
+}
+
+type matchFailure struct {
+	nodes   *Node
+	repeat  *NodeOneOrMore
+	word    int
+	offset  int
+	used    string
+	context string
+}
 
 func (matcher *ArgumentsMatcher) Match(
 	args []string,
 	variants []Grammar,
 	options []Option,
-) (map[string]interface{}, error) {
-	//var result map[string]interface{}
-
-	//for _, variant := range variants {
-	//    for {
-	//        for _, token := range variant {
-	//            matcher.matchToken(args, token, result)
-	//        }
-	//    }
-	//}
-
-	//return result, nil
-	return nil, nil
-}
-
-//func (matcher *ArgumentsMatcher) matchToken(
-//    arg string,
-//    token Token,
-//    result map[string]interface{},
-//) (tail string, matched bool) {
-//    switch token := token.(type) {
-//    case TokenSeparator:
-//        if arg != "" {
-//            return "", false
-//        }
-
-//        return "", true
-
-//    case TokenOption:
-//        return "", false
-//    }
-//}
+) (*Result, error) {
+	trees, err := getTrees(variants)
+	if err != nil {
+		return nil, err
+	}
+
+	return matcher.matchTrees(args, trees, options)
+}
+
+// matchTrees matches args against trees built from variants beforehand, it
+// doesn't modify trees, so they can be shared by concurrent calls.
+func (matcher *ArgumentsMatcher) matchTrees(
+	args []string,
+	trees []*NodeRequired,
+	options []Option,
+) (*Result, error) {
+	options = matcher.collectOptions(trees, options)
+
+	parser := &ArgumentsParser{
+		Options:      options,
+		OptionsFirst: matcher.OptionsFirst,
+		Abbreviate:   matcher.Abbreviate,
+	}
+
+	arguments, err := parser.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := matcher.getInput(args, arguments.Grammar, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		expected  = map[int][]string{}
+		diagnoses = []*matchDiagnosis{}
+	)
+
+	for _, tree := range trees {
+		diagnosis := &matchDiagnosis{
+			commands:   map[string]bool{},
+			unexpected: -1,
+			unused:     -1,
+			expected:   expected,
+		}
+
+		Walk(tree, func(node Node) bool {
+			if command, ok := node.(*NodeCommand); ok {
+				diagnosis.commands[command.Name] = true
+			}
+
+			return true
+		})
+
+		state := &matchState{
+			input:     input,
+			used:      make([]bool, len(input.options)),
+			unique:    map[string]bool{},
+			failures:  map[matchFailure]bool{},
+			diagnosis: diagnosis,
+			optional:  -1,
+		}
+
+		for key, count := range getCounts(tree, options) {
+			state.unique[key] = count == 1 && findOption(options, key) != nil
+		}
+
+		if matcher.matchNode(tree, options, state, state.isDone) {
+			return matcher.getResult(trees, options, state.bindings), nil
+		}
+
+		diagnoses = append(diagnoses, diagnosis)
+	}
+
+	return nil, matcher.getError(args, input, options, diagnoses)
+}
+
+// Complete returns candidates which can follow args in any of variants.
+// Options are offered when they are reached by a match of args regardless
+// of their position in the variant, because they can be given anywhere.
+// Options which are given already are not offered again unless they can be
+// repeated.
+func (matcher *ArgumentsMatcher) Complete(
+	args []string,
+	variants []Grammar,
+	options []Option,
+) []Candidate {
+	trees, err := getTrees(variants)
+	if err != nil {
+		return nil
+	}
+
+	return matcher.completeTrees(args, trees, options)
+}
+
+func (matcher *ArgumentsMatcher) completeTrees(
+	args []string,
+	trees []*NodeRequired,
+	options []Option,
+) []Candidate {
+	options = matcher.collectOptions(trees, options)
+
+	parser := &ArgumentsParser{
+		Options:      options,
+		OptionsFirst: matcher.OptionsFirst,
+		Abbreviate:   matcher.Abbreviate,
+	}
+
+	arguments, err := parser.Parse(args)
+	if err != nil {
+		return nil
+	}
+
+	input, err := matcher.getInput(args, arguments.Grammar, options)
+	if required, ok := err.(ErrOptionRequiresValue); ok &&
+		required.Index == len(args)-1 {
+		return []Candidate{{
+			Kind:        CandidateKindArgument,
+			Value:       required.Option.Value,
+			Description: getCompletionDescription(required.Option),
+		}}
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	var (
+		candidates = []Candidate{}
+		offered    = map[Candidate]bool{}
+		named      = []Candidate{}
+	)
+
+	for _, tree := range trees {
+		if !matcher.isCompletable(input, getOptionNames(tree, options)) {
+			continue
+		}
+
+		state := &matchState{
+			input:    input,
+			used:     make([]bool, len(input.options)),
+			unique:   map[string]bool{},
+			failures: map[matchFailure]bool{},
+			optional: -1,
+			diagnosis: &matchDiagnosis{
+				commands: map[string]bool{},
+				expected: map[int][]string{},
+			},
+			completing: true,
+			committed:  map[Candidate]bool{},
+			viables:    map[matchFailure]bool{},
+		}
+
+		matcher.matchNode(tree, options, state, state.isDone)
+
+		for _, candidate := range state.candidates {
+			if candidate.Kind != CandidateKindOption {
+				candidates = append(candidates, candidate)
+
+				continue
+			}
+
+			if input.end >= 0 || matcher.OptionsFirst && len(input.words) > 0 {
+				continue
+			}
+
+			named = append(named, candidate)
+		}
+	}
+
+	unique := []Candidate{}
+
+	for _, candidate := range append(candidates, named...) {
+		if offered[candidate] {
+			continue
+		}
+
+		offered[candidate] = true
+
+		unique = append(unique, candidate)
+	}
+
+	return unique
+}
+
+// isCompletable checks that all options given in input are referenced by
+// the variant with the given option names.
+func (matcher *ArgumentsMatcher) isCompletable(
+	input *matchInput,
+	names []string,
+) bool {
+	for _, option := range input.options {
+		found := false
+
+		for _, name := range names {
+			if name == option.Name {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getError explains failure relatively to the variant which got furthest,
+// preferring variants which know exactly what is missing or left over.
+func (matcher *ArgumentsMatcher) getError(
+	args []string,
+	input *matchInput,
+	options []Option,
+	diagnoses []*matchDiagnosis,
+) error {
+	var diagnosis *matchDiagnosis
+
+	for _, candidate := range diagnoses {
+		if diagnosis == nil || candidate.isCloserThan(diagnosis) {
+			diagnosis = candidate
+		}
+	}
+
+	if diagnosis == nil {
+		return ErrNoVariantMatched{
+			Args:  args,
+			Index: len(args),
+		}
+	}
+
+	switch {
+	case diagnosis.missing != "" && !diagnosis.isAmbiguous(diagnoses):
+		return ErrMissingArgument{
+			Index:   len(args),
+			Name:    diagnosis.missing,
+			Command: strings.Join(diagnosis.path, " "),
+		}
+
+	case diagnosis.unexpected >= 0:
+		index := input.wordIndexes[diagnosis.unexpected]
+
+		return ErrUnexpectedArgument{
+			Index: index,
+			Value: args[index],
+			After: diagnosis.after,
+			Suggestions: getSuggestions(
+				args[index],
+				diagnosis.expected[diagnosis.unexpected],
+			),
+		}
+
+	case diagnosis.unused >= 0:
+		var (
+			index  = input.optionIndexes[diagnosis.unused]
+			option = findOption(options, input.options[diagnosis.unused].Name)
+		)
+
+		return ErrUnexpectedArgument{
+			Index:  index,
+			Option: option,
+		}
+	}
+
+	var (
+		index       = len(args)
+		suggestions []string
+	)
+
+	if diagnosis.reached < len(input.wordIndexes) {
+		index = input.wordIndexes[diagnosis.reached]
+
+		suggestions = getSuggestions(
+			args[index],
+			diagnosis.expected[diagnosis.reached],
+		)
+	}
+
+	return ErrNoVariantMatched{
+		Args:        args,
+		Index:       index,
+		Suggestions: suggestions,
+	}
+}
+
+func (matcher *ArgumentsMatcher) collectOptions(
+	trees []*NodeRequired,
+	options []Option,
+) []Option {
+	collected := append([]Option{}, options...)
+
+	for _, tree := range trees {
+		Walk(tree, func(node Node) bool {
+			option, ok := node.(*NodeOption)
+			if !ok {
+				return true
+			}
+
+			if findOption(collected, option.Name) != nil {
+				return true
+			}
+
+			collected = append(collected, Option{
+				Names: []string{option.Name},
+				Value: option.Value,
+			})
+
+			return true
+		})
+	}
+
+	return collected
+}
+
+func (matcher *ArgumentsMatcher) getInput(
+	args []string,
+	grammar Grammar,
+	options []Option,
+) (*matchInput, error) {
+	var (
+		input  = &matchInput{end: -1}
+		groups = [][]Token{{}}
+	)
+
+	for _, token := range grammar {
+		if _, ok := token.(*TokenSeparator); ok {
+			groups = append(groups, []Token{})
+
+			continue
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], token)
+	}
+
+	for index := 0; index < len(groups); index++ {
+		for position, token := range groups[index] {
+			switch token := token.(type) {
+			case *TokenOption:
+				option, err := matcher.findOption(options, token.Name)
+				if ambiguous, ok := err.(ErrAmbiguousOption); ok {
+					ambiguous.Index = index
+
+					return nil, ambiguous
+				}
+
+				if option == nil {
+					return nil, ErrUnknownOption{
+						Index:       index,
+						Name:        token.Name,
+						Suggestions: getSuggestions(token.Name, getNames(options)),
+					}
+				}
+
+				switch {
+				case !option.HasArgument() && token.Value != "":
+					return nil, ErrUnexpectedArgument{
+						Index:  index,
+						Value:  token.Value,
+						Option: option,
+					}
+
+				case option.HasArgument() && token.Value == "":
+					if position < len(groups[index])-1 ||
+						index+1 >= len(args) || args[index+1] == "--" {
+						return nil, ErrOptionRequiresValue{
+							Index:  index,
+							Name:   token.Name,
+							Option: *option,
+						}
+					}
+
+					index++
+
+					token.Value = args[index]
+				}
+
+				token.Name = option.GetName()
+
+				input.options = append(input.options, *token)
+				input.optionIndexes = append(input.optionIndexes, index)
+
+			case *TokenOptionsEnd:
+				input.end = len(input.words)
+
+				input.words = append(input.words, "--")
+				input.wordIndexes = append(input.wordIndexes, index)
+
+			case *TokenPositionalArgument:
+				input.words = append(input.words, token.Value)
+				input.wordIndexes = append(input.wordIndexes, index)
+			}
+		}
+	}
+
+	return input, nil
+}
+
+func (matcher *ArgumentsMatcher) findOption(
+	options []Option,
+	name string,
+) (*Option, error) {
+	option := findOption(options, name)
+	if option != nil {
+		return option, nil
+	}
+
+	if !matcher.Abbreviate || !strings.HasPrefix(name, "--") {
+		return nil, nil
+	}
+
+	candidates := []Option{}
+
+	for index := range options {
+		for _, alias := range options[index].Names {
+			if !strings.HasPrefix(alias, "--") {
+				continue
+			}
+
+			if !strings.HasPrefix(alias, name) {
+				continue
+			}
+
+			candidates = append(candidates, options[index])
+
+			option = &options[index]
+
+			break
+		}
+	}
+
+	if len(candidates) > 1 {
+		return nil, ErrAmbiguousOption{
+			Name:       name,
+			Candidates: candidates,
+		}
+	}
+
+	return option, nil
+}
+
+func (matcher *ArgumentsMatcher) matchNodes(
+	nodes []Node,
+	options []Option,
+	state *matchState,
+	next func() bool,
+) bool {
+	if len(nodes) == 0 {
+		return next()
+	}
+
+	tail := func() bool {
+		return matcher.matchNodes(nodes[1:], options, state, next)
+	}
+
+	failure := state.getFailure()
+	failure.nodes = &nodes[0]
+
+	if state.isFailed(failure) {
+		return false
+	}
+
+	ends := state.ends
+
+	if matcher.matchNode(nodes[0], options, state, tail) {
+		return true
+	}
+
+	state.fail(failure, ends)
+
+	return false
+}
+
+func (matcher *ArgumentsMatcher) matchNode(
+	node Node,
+	options []Option,
+	state *matchState,
+	next func() bool,
+) bool {
+	switch node := node.(type) {
+	case *NodeRequired:
+		return matcher.matchNodes(node.Children, options, state, next)
+
+	case *NodeOptional:
+		return matcher.matchOptional(node, options, state, next)
+
+	case *NodeEither:
+		for _, child := range node.Children {
+			if matcher.matchNode(child, options, state, next) {
+				return true
+			}
+		}
+
+		return false
+
+	case *NodeOneOrMore:
+		return matcher.matchRepeat(node, options, state, next)
+	}
+
+	return matcher.matchLeaf(node, options, state, next)
+}
+
+func (matcher *ArgumentsMatcher) matchOptional(
+	node *NodeOptional,
+	options []Option,
+	state *matchState,
+	next func() bool,
+) bool {
+	var (
+		word     = state.word
+		offset   = state.offset
+		bindings = len(state.bindings)
+		skip     = true
+	)
+
+	optional := state.optional
+
+	state.optional = bindings
+
+	defer func() {
+		state.optional = optional
+	}()
+
+	// Skipping optional group that consumed only options which are not
+	// referenced anywhere else can't lead to a match, so it's pruned to
+	// avoid exponential backtracking on long lists of optional flags.
+	matched := matcher.matchNodes(node.Children, options, state, func() bool {
+		if state.word == word && state.offset == offset &&
+			state.isUnique(bindings) {
+			skip = false
+		}
+
+		state.optional = optional
+
+		defer func() {
+			state.optional = bindings
+		}()
+
+		return next()
+	})
+
+	if matched {
+		return true
+	}
+
+	if skip {
+		state.optional = optional
+
+		return next()
+	}
+
+	return false
+}
+
+func (matcher *ArgumentsMatcher) matchRepeat(
+	node *NodeOneOrMore,
+	options []Option,
+	state *matchState,
+	next func() bool,
+) bool {
+	failure := state.getFailure()
+	failure.repeat = node
+
+	if state.isFailed(failure) {
+		return false
+	}
+
+	var (
+		bindings = len(state.bindings)
+		context  = state.context
+		ends     = state.ends
+	)
+
+	state.context = context + "|" + failure.getPosition()
+
+	defer func() {
+		state.context = context
+	}()
+
+	matched := matcher.matchNodes(node.Children, options, state, func() bool {
+		iteration := state.context
+
+		state.context = context
+
+		defer func() {
+			state.context = iteration
+		}()
+
+		if len(state.bindings) > bindings {
+			more := matcher.matchLeaf(
+				&NodeSeparator{},
+				options,
+				state,
+				func() bool {
+					return matcher.matchRepeat(node, options, state, next)
+				},
+			)
+
+			if more {
+				return true
+			}
+		}
+
+		return next()
+	})
+
+	if !matched {
+		state.fail(failure, ends)
+	}
+
+	return matched
+}
+
+func (matcher *ArgumentsMatcher) matchLeaf(
+	node Node,
+	options []Option,
+	state *matchState,
+	next func() bool,
+) bool {
+	switch node := node.(type) {
+	case *NodeSeparator:
+		if state.offset == 0 {
+			return next()
+		}
+
+		if state.offset != len(state.input.words[state.word]) {
+			return false
+		}
+
+		state.word++
+		state.offset = 0
+
+		if next() {
+			return true
+		}
+
+		state.word--
+		state.offset = len(state.input.words[state.word])
+
+		return false
+
+	case *NodeCommand:
+		rest, ok := state.getRest()
+		if !ok {
+			state.miss(node.Name)
+
+			return state.offer(CandidateKindWord, node.Name, next)
+		}
+
+		// Command which matches only part of the word is still expected
+		// there, because the word may be a typo of the command.
+		if rest != node.Name && state.offset == 0 {
+			state.diagnosis.expected[state.word] = append(
+				state.diagnosis.expected[state.word],
+				node.Name,
+			)
+		}
+
+		if !strings.HasPrefix(rest, node.Name) {
+			return false
+		}
+
+		return state.try(node.Name, "", len(node.Name), next)
+
+	case *NodeArgument:
+		rest, ok := state.getRest()
+		if !ok {
+			state.miss(node.Name)
+
+			return state.offer(CandidateKindArgument, node.Name, next)
+		}
+
+		// Empty word can't be consumed partially, so it's consumed as a
+		// whole by moving to the next word.
+		if state.input.words[state.word] == "" {
+			state.word++
+
+			if state.try(node.Name, "", 0, next) {
+				return true
+			}
+
+			state.word--
+
+			return false
+		}
+
+		for end := len(rest); end > 0; end-- {
+			if state.try(node.Name, rest[:end], end, next) {
+				return true
+			}
+		}
+
+		return false
+
+	case *NodeOption:
+		name := node.Name
+
+		option := findOption(options, name)
+		if option != nil {
+			name = option.GetName()
+		}
+
+		// Occurrences with the same value are interchangeable, so only the
+		// first unused one is tried to avoid trying all their orderings.
+		tried := map[string]bool{}
+
+		for index, argument := range state.input.options {
+			if state.used[index] || argument.Name != name ||
+				tried[argument.Value] {
+				continue
+			}
+
+			tried[argument.Value] = true
+
+			state.used[index] = true
+
+			if state.try(name, argument.Value, 0, next) {
+				return true
+			}
+
+			state.used[index] = false
+		}
+
+		state.miss(name)
+
+		// Options may be given later anywhere, so missing ones don't stop
+		// completion.
+		if state.completing {
+			if option == nil {
+				option = &Option{Names: []string{name}}
+			}
+
+			return state.pend(getOptionCandidates(*option), next)
+		}
+
+		return false
+
+	case *NodeOptionsEnd:
+		if state.offset != 0 || state.word != state.input.end {
+			if state.input.end < 0 {
+				return state.offer(CandidateKindWord, "--", next)
+			}
+
+			return false
+		}
+
+		return state.try("--", "", len("--"), next)
+
+	case *NodeStdin:
+		rest, ok := state.getRest()
+		if !ok {
+			return state.offer(CandidateKindWord, "-", next)
+		}
+
+		if state.offset != 0 || rest != "-" {
+			return false
+		}
+
+		return state.try("-", "", len(rest), next)
+
+	case *NodeOptionsShortcut:
+		var (
+			used     = []int{}
+			bindings = len(state.bindings)
+			pending  = []Candidate{}
+		)
+
+		for _, option := range node.Options {
+			var (
+				name  = option.GetName()
+				found = false
+			)
+
+			for index, argument := range state.input.options {
+				if state.used[index] || argument.Name != name {
+					continue
+				}
+
+				state.used[index] = true
+
+				state.bindings = append(state.bindings, matchBinding{
+					key:   name,
+					value: argument.Value,
+				})
+
+				used = append(used, index)
+
+				found = true
+
+				break
+			}
+
+			if !found && state.completing {
+				pending = append(pending, getOptionCandidates(option)...)
+			}
+		}
+
+		if state.pend(pending, next) {
+			return true
+		}
+
+		for _, index := range used {
+			state.used[index] = false
+		}
+
+		state.bindings = state.bindings[:bindings]
+
+		return false
+
+	default:
+		return next()
+	}
+}
+
+func (matcher *ArgumentsMatcher) getResult(
+	trees []*NodeRequired,
+	options []Option,
+	bindings []matchBinding,
+) *Result {
+	var (
+		result   = map[string]interface{}{}
+		repeated = getRepeatedKeys(trees, options)
+		valued   = map[string]bool{}
+	)
+
+	for _, option := range options {
+		name := option.GetName()
+
+		if !option.HasArgument() {
+			result[name] = false
+
+			if repeated[name] {
+				result[name] = 0
+			}
+
+			continue
+		}
+
+		valued[name] = true
+
+		value, ok := option.GetDefault()
+
+		switch {
+		case repeated[name] && ok:
+			result[name] = strings.Fields(value)
+		case repeated[name]:
+			result[name] = []string{}
+		case ok:
+			result[name] = value
+		default:
+			result[name] = nil
+		}
+	}
+
+	for _, tree := range trees {
+		Walk(tree, func(node Node) bool {
+			switch node := node.(type) {
+			case *NodeCommand:
+				result[node.Name] = false
+
+				if repeated[node.Name] {
+					result[node.Name] = 0
+				}
+
+			case *NodeOptionsEnd:
+				result["--"] = false
+
+			case *NodeStdin:
+				result["-"] = false
+
+			case *NodeArgument:
+				valued[node.Name] = true
+
+				result[node.Name] = nil
+
+				if repeated[node.Name] {
+					result[node.Name] = []string{}
+				}
+			}
+
+			return true
+		})
+	}
+
+	defaults := map[string]bool{}
+
+	for _, binding := range bindings {
+		switch {
+		case !valued[binding.key] && repeated[binding.key]:
+			result[binding.key] = result[binding.key].(int) + 1
+
+		case !valued[binding.key]:
+			result[binding.key] = true
+
+		case repeated[binding.key]:
+			if !defaults[binding.key] {
+				defaults[binding.key] = true
+
+				result[binding.key] = []string{}
+			}
+
+			result[binding.key] = append(
+				result[binding.key].([]string),
+				binding.value,
+			)
+
+		default:
+			result[binding.key] = binding.value
+		}
+	}
+
+	return &Result{
+		Values:  result,
+		Options: options,
+	}
+}
+
+func (state *matchState) getRest() (string, bool) {
+	if state.word >= len(state.input.words) {
+		return "", false
+	}
+
+	if state.word == state.input.end {
+		return "", false
+	}
+
+	return state.input.words[state.word][state.offset:], true
+}
+
+func (state *matchState) try(
+	key string,
+	value string,
+	length int,
+	next func() bool,
+) bool {
+	state.bindings = append(state.bindings, matchBinding{
+		key:   key,
+		value: value,
+	})
+
+	state.offset += length
+
+	state.diagnosis.reach(state.getWord())
+
+	if next() {
+		return true
+	}
+
+	state.offset -= length
+
+	state.bindings = state.bindings[:len(state.bindings)-1]
+
+	return false
+}
+
+func (state *matchState) getFailure() matchFailure {
+	used := make([]byte, len(state.used))
+
+	for index, flag := range state.used {
+		if flag {
+			used[index] = 1
+		}
+	}
+
+	return matchFailure{
+		word:    state.word,
+		offset:  state.offset,
+		used:    string(used),
+		context: state.context,
+	}
+}
+
+func (failure matchFailure) getPosition() string {
+	return strconv.Itoa(failure.word) + ":" + strconv.Itoa(failure.offset) +
+		":" + failure.used
+}
+
+func (state *matchState) isUnique(bindings int) bool {
+	for _, binding := range state.bindings[bindings:] {
+		if !state.unique[binding.key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (state *matchState) isDone() bool {
+	if state.completing {
+		if state.getWord() >= len(state.input.words) && state.isUsed() {
+			state.commit()
+		}
+
+		return false
+	}
+
+	if word := state.getWord(); word < len(state.input.words) {
+		// Word consumed partially is not reported as unexpected, because
+		// it's not clear what it follows.
+		partial := state.offset > 0 && word == state.word
+
+		if !partial && word > state.diagnosis.unexpected {
+			state.diagnosis.unexpected = word
+			state.diagnosis.after = state.getLastPositional()
+		}
+
+		return false
+	}
+
+	for index, used := range state.used {
+		if !used {
+			if state.diagnosis.unused < 0 {
+				state.diagnosis.unused = index
+			}
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// miss records required element which is reached after all words are
+// consumed as missing.
+func (state *matchState) miss(name string) {
+	if state.word < len(state.input.words) || state.diagnosis.missing != "" {
+		return
+	}
+
+	if state.optional >= 0 && len(state.bindings) == state.optional {
+		return
+	}
+
+	state.diagnosis.missing = name
+	state.diagnosis.path = state.getPath()
+}
+
+func (state *matchState) isUsed() bool {
+	for _, used := range state.used {
+		if !used {
+			return false
+		}
+	}
+
+	return true
+}
+
+// offer makes candidate pending if completing and all words are consumed,
+// then goes on as if it was typed to reach options which may follow. Only
+// the first element after the words is offered.
+func (state *matchState) offer(
+	kind CandidateKind,
+	value string,
+	next func() bool,
+) bool {
+	if !state.completing || state.word < len(state.input.words) {
+		return false
+	}
+
+	if state.beyond {
+		return next()
+	}
+
+	context := state.context
+
+	state.beyond = true
+	state.context += "|beyond"
+
+	matched := state.pend([]Candidate{{Kind: kind, Value: value}}, next)
+
+	state.beyond = false
+	state.context = context
+
+	return matched
+}
+
+func (state *matchState) pend(candidates []Candidate, next func() bool) bool {
+	pending := len(state.pending)
+
+	state.pending = append(state.pending, candidates...)
+
+	matched := next()
+
+	state.pending = state.pending[:pending]
+
+	return matched
+}
+
+// commit records pending candidates when viable path is reached.
+func (state *matchState) commit() {
+	state.ends++
+
+	for _, candidate := range state.pending {
+		if !state.committed[candidate] {
+			state.committed[candidate] = true
+
+			state.candidates = append(state.candidates, candidate)
+		}
+	}
+}
+
+// isFailed checks whether matching from the state has failed already. When
+// completing, all matches fail, so pending candidates are committed if the
+// rest of the path is known to be viable.
+func (state *matchState) isFailed(failure matchFailure) bool {
+	if !state.failures[failure] {
+		return false
+	}
+
+	if state.viables[failure] {
+		state.commit()
+	}
+
+	return true
+}
+
+// fail remembers that matching from the state has failed, and whether a
+// viable path was reached since the given number of ends.
+func (state *matchState) fail(failure matchFailure, ends int) {
+	state.failures[failure] = true
+
+	if state.ends > ends {
+		state.viables[failure] = true
+	}
+}
+
+// getWord returns index of the first word which is not consumed yet.
+func (state *matchState) getWord() int {
+	if state.offset > 0 && state.offset == len(state.input.words[state.word]) {
+		return state.word + 1
+	}
+
+	return state.word
+}
+
+// getPath returns commands matched so far.
+func (state *matchState) getPath() []string {
+	path := []string{}
+
+	for _, binding := range state.bindings {
+		if state.diagnosis.commands[binding.key] {
+			path = append(path, binding.key)
+		}
+	}
+
+	return path
+}
+
+func (state *matchState) getLastPositional() string {
+	for index := len(state.bindings) - 1; index >= 0; index-- {
+		key := state.bindings[index].key
+
+		if !strings.HasPrefix(key, "-") {
+			return key
+		}
+	}
+
+	return ""
+}
+
+func (diagnosis *matchDiagnosis) isCloserThan(other *matchDiagnosis) bool {
+	if diagnosis.reached != other.reached {
+		return diagnosis.reached > other.reached
+	}
+
+	if diagnosis.isExplained() != other.isExplained() {
+		return diagnosis.isExplained()
+	}
+
+	return len(diagnosis.path) > len(other.path)
+}
+
+// isAmbiguous checks whether other variants which got as far through the
+// same number of commands tell that something else is missing, so it's not
+// clear what to report.
+func (diagnosis *matchDiagnosis) isAmbiguous(diagnoses []*matchDiagnosis) bool {
+	for _, other := range diagnoses {
+		if other.reached == diagnosis.reached && other.missing != "" &&
+			other.missing != diagnosis.missing &&
+			len(other.path) == len(diagnosis.path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (diagnosis *matchDiagnosis) isExplained() bool {
+	return diagnosis.missing != "" || diagnosis.unexpected >= 0
+}
+
+func (diagnosis *matchDiagnosis) reach(word int) {
+	if word > diagnosis.reached {
+		diagnosis.reached = word
+	}
+}
+
+func getRepeatedKeys(
+	trees []*NodeRequired,
+	options []Option,
+) map[string]bool {
+	repeated := map[string]bool{}
+
+	for _, tree := range trees {
+		for key, count := range getCounts(tree, options) {
+			if count > 1 {
+				repeated[key] = true
+			}
+		}
+	}
+
+	return repeated
+}
+
+func getCounts(node Node, options []Option) map[string]int {
+	counts := map[string]int{}
+
+	switch node := node.(type) {
+	case *NodeEither:
+		for _, child := range node.Children {
+			mergeMaxCounts(counts, getCounts(child, options))
+		}
+
+	case *NodeOneOrMore:
+		for _, child := range node.Children {
+			for key, count := range getCounts(child, options) {
+				counts[key] += count * 2
+			}
+		}
+
+	default:
+		if key := getKey(node, options); key != "" {
+			counts[key] = 1
+		}
+
+		for _, child := range node.GetChildren() {
+			for key, count := range getCounts(child, options) {
+				counts[key] += count
+			}
+		}
+	}
+
+	return counts
+}
+
+func mergeMaxCounts(target map[string]int, source map[string]int) {
+	for key, count := range source {
+		if count > target[key] {
+			target[key] = count
+		}
+	}
+}
+
+func getKey(node Node, options []Option) string {
+	switch node := node.(type) {
+	case *NodeCommand:
+		return node.Name
+
+	case *NodeArgument:
+		return node.Name
+
+	case *NodeOption:
+		if option := findOption(options, node.Name); option != nil {
+			return option.GetName()
+		}
+
+		return node.Name
+	}
+
+	return ""
+}
+
+// getOptionNames returns canonical names of options referenced by the tree,
+// including options behind options shortcuts.
+func getOptionNames(tree Node, options []Option) []string {
+	var (
+		names = []string{}
+		found = map[string]bool{}
+	)
+
+	add := func(name string) {
+		if !found[name] {
+			found[name] = true
+
+			names = append(names, name)
+		}
+	}
+
+	Walk(tree, func(node Node) bool {
+		switch node := node.(type) {
+		case *NodeOption:
+			add(getKey(node, options))
+
+		case *NodeOptionsShortcut:
+			for _, option := range node.Options {
+				add(option.GetName())
+			}
+		}
+
+		return true
+	})
+
+	return names
+}
+
+func getOptionCandidates(option Option) []Candidate {
+	candidates := []Candidate{}
+
+	for _, name := range option.Names {
+		candidates = append(candidates, Candidate{
+			Kind:        CandidateKindOption,
+			Value:       name,
+			Description: getCompletionDescription(option),
+		})
+	}
+
+	return candidates
+}
+
+func getNames(options []Option) []string {
+	names := []string{}
+
+	for _, option := range options {
+		names = append(names, option.Names...)
+	}
+
+	return names
+}
+
+func findOption(options []Option, name string) *Option {
+	for index := range options {
+		for _, alias := range options[index].Names {
+			if alias == name {
+				return &options[index]
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package docopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMatcherVariants(test *assert.Assertions, section string) []Grammar {
+	parser := &UsageParser{}
+
+	usage, err := parser.Parse(section)
+	test.NoError(err)
+
+	variants := []Grammar{}
+
+	for _, grammar := range usage.Variants {
+		variant := Grammar{&TokenGroup{Opened: true, Required: true}}
+		variant = append(variant, grammar...)
+		variant = append(variant, &TokenGroup{Required: true})
+
+		test.NoError(variant.Balance())
+
+		variants = append(variants, variant)
+	}
+
+	return variants
+}
+
+func Test_ArgumentsMatcher_MatchesStaticWordsAndArguments(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `
+		blah add <name>
+		blah remove <name>
+	`)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match([]string{`remove`, `x`}, variants, nil)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`add`:    false,
+		`remove`: true,
+		`<name>`: `x`,
+	}, actual.Values)
+}
+
+func Test_ArgumentsMatcher_MatchesOptionsInAnyOrder(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah [-v] [--data=<value>] <file>`)
+
+	options := []Option{
+		{Names: []string{`-v`, `--verbose`}},
+		{
+			Names:       []string{`--data`},
+			Value:       `<value>`,
+			Description: []string{`Data [default: none].`},
+		},
+	}
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match(
+		[]string{`x`, `--data=y`, `-v`},
+		variants,
+		options,
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--verbose`: true,
+		`--data`:    `y`,
+		`<file>`:    `x`,
+	}, actual.Values)
+
+	actual, err = matcher.Match([]string{`x`}, variants, options)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--verbose`: false,
+		`--data`:    `none`,
+		`<file>`:    `x`,
+	}, actual.Values)
+}
+
+func Test_ArgumentsMatcher_CountsRepeatedElements(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah [-v] [-v] go [go] <x> [<x>]`)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match(
+		[]string{`-v`, `go`, `go`, `a`, `b`, `-v`},
+		variants,
+		nil,
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`-v`:  2,
+		`go`:  2,
+		`<x>`: []string{`a`, `b`},
+	}, actual.Values)
+}
+
+func Test_ArgumentsMatcher_MatchesArgumentsInsideWord(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah key[=<value>]`)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match([]string{`key=x`}, variants, nil)
+
+	test.NoError(err)
+	test.EqualValues(`x`, actual.Values[`<value>`])
+
+	actual, err = matcher.Match([]string{`key`}, variants, nil)
+
+	test.NoError(err)
+	test.EqualValues(true, actual.Values[`key`])
+	test.Nil(actual.Values[`<value>`])
+}
+
+func Test_ArgumentsMatcher_ReturnsErrorWhenNoVariantMatches(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah add <name>`)
+
+	matcher := &ArgumentsMatcher{}
+
+	for _, testcase := range []struct {
+		args  []string
+		err   error
+		index int
+	}{
+		{[]string{}, ErrMissingArgument{}, 0},
+		{[]string{`add`}, ErrMissingArgument{}, 1},
+		{[]string{`add`, `x`, `y`}, ErrUnexpectedArgument{}, 2},
+		{[]string{`add`, `x`, `--force`}, ErrUnknownOption{}, 2},
+		{[]string{`remove`, `x`}, ErrNoVariantMatched{}, 0},
+	} {
+		actual, err := matcher.Match(testcase.args, variants, nil)
+
+		test.Nil(actual)
+		test.IsType(testcase.err, err)
+		test.Equal(testcase.index, err.(ErrInput).GetIndex())
+	}
+}
+
+func Test_ArgumentsMatcher_ConsumesSeparatedOptionValue(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah [--data=<value>] [-o <file>] [<arg>]`)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match(
+		[]string{`--data`, `value`, `-o`, `--data`},
+		variants,
+		nil,
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--data`: `value`,
+		`-o`:     `--data`,
+		`<arg>`:  nil,
+	}, actual.Values)
+}
+
+func Test_ArgumentsMatcher_ReturnsErrorOnOptionArgumentMismatch(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah [--help] [--data=<value>]`)
+
+	matcher := &ArgumentsMatcher{}
+
+	_, err := matcher.Match([]string{`--data`}, variants, nil)
+	test.EqualError(err, `option --data requires argument`)
+
+	_, err = matcher.Match([]string{`--help=x`}, variants, nil)
+	test.EqualError(err, `option --help does not take an argument`)
+}
+
+func Test_ArgumentsMatcher_MatchesRepeatedElements(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(
+		test,
+		`blah [(-v | -q)...] [--path=<path>]... <file>... [<x> <y>]...`,
+	)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match(
+		[]string{`-v`, `a`, `-q`, `--path=1`, `-v`, `b`, `--path`, `2`},
+		variants,
+		nil,
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`-v`:     2,
+		`-q`:     1,
+		`--path`: []string{`1`, `2`},
+		`<file>`: []string{`a`, `b`},
+		`<x>`:    []string{},
+		`<y>`:    []string{},
+	}, actual.Values)
+
+	_, err = matcher.Match([]string{}, variants, nil)
+
+	test.IsType(ErrMissingArgument{}, err)
+}
+
+func Test_ArgumentsMatcher_MatchesRepeatedOptionalGroup(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah <file> [<x> <y>]...`)
+
+	matcher := &ArgumentsMatcher{}
+
+	actual, err := matcher.Match(
+		[]string{`a`, `1`, `2`, `3`, `4`},
+		variants,
+		nil,
+	)
+
+	test.NoError(err)
+	test.EqualValues(`a`, actual.Values[`<file>`])
+	test.EqualValues([]string{`1`, `3`}, actual.Values[`<x>`])
+	test.EqualValues([]string{`2`, `4`}, actual.Values[`<y>`])
+
+	_, err = matcher.Match([]string{`a`, `1`, `2`, `3`}, variants, nil)
+
+	test.IsType(ErrMissingArgument{}, err)
+}
+
+func Test_ArgumentsMatcher_FailsOnRepeatedFlagsWithTypo(t *testing.T) {
+	test := assert.New(t)
+
+	variants := getMatcherVariants(test, `blah [-v]... <file>`)
+
+	matcher := &ArgumentsMatcher{}
+
+	flags := []string{}
+
+	for i := 0; i < 20; i++ {
+		flags = append(flags, `-v`)
+	}
+
+	actual, err := matcher.Match(append(flags, `a`), variants, nil)
+
+	test.NoError(err)
+	test.EqualValues(20, actual.Values[`-v`])
+
+	_, err = matcher.Match(append(flags, `a`, `b`), variants, nil)
+
+	test.EqualError(err, `unexpected argument "b" after <file>`)
+}
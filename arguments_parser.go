@@ -1,48 +1,188 @@
 package docopt
 
-type ArgumentsParser struct{}
+import (
+	"unicode/utf8"
+)
+
+type ArgumentsParser struct {
+	Options      []Option
+	OptionsFirst bool
+	Abbreviate   bool
+}
 
 func (parser *ArgumentsParser) Parse(args []string) (*Arguments, error) {
 	var (
 		arguments Arguments
+		ended     bool
+		value     bool
 	)
 
-	for _, arg := range args {
-		if len(arguments.Grammar) > 0 {
-			arguments.Grammar = append(arguments.Grammar, TokenSeparator{})
+	for index, arg := range args {
+		if index > 0 {
+			arguments.Grammar = append(arguments.Grammar, &TokenSeparator{})
 		}
 
-		scanner := NewScanner(arg)
+		if ended || value {
+			value = false
 
-		for scanner.Scan() {
-			token := parser.parseToken(scanner)
+			arguments.Grammar = append(
+				arguments.Grammar,
+				&TokenPositionalArgument{Value: arg},
+			)
 
-			if token == nil {
-				break
-			}
+			continue
+		}
+
+		// Argument is a single unit even if it has line breaks, so it's not
+		// split into lines as doc is.
+		scanner := &Scanner{Line: arg, Tail: arg}
+
+		tokens, err := parser.parseTokens(scanner)
+		switch typed := err.(type) {
+		case ErrUnknownOption:
+			typed.Index = index
+
+			return nil, typed
+
+		case ErrAmbiguousOptionGroup:
+			typed.Index = index
+
+			return nil, typed
+		}
 
-			arguments.Grammar = append(arguments.Grammar, token)
+		if err != nil {
+			return nil, err
 		}
+
+		switch token := tokens[len(tokens)-1].(type) {
+		case *TokenOptionsEnd:
+			ended = true
+
+		case *TokenPositionalArgument:
+			ended = parser.OptionsFirst
+
+		case *TokenOption:
+			option, _ := (&ArgumentsMatcher{
+				Abbreviate: parser.Abbreviate,
+			}).findOption(parser.Options, token.Name)
+
+			value = option != nil && option.HasArgument() &&
+				token.Value == ""
+		}
+
+		arguments.Grammar = append(arguments.Grammar, tokens...)
 	}
 
 	return &arguments, nil
 }
 
+func (parser *ArgumentsParser) parseTokensShortOptions(
+	scanner *Scanner,
+) ([]Token, error) {
+	if len(parser.Options) == 0 {
+		return nil, nil
+	}
+
+	tail := scanner.Tail
+
+	matches := scanner.Match(MatcherShortOptionsGroup)
+	if matches == nil {
+		return nil, nil
+	}
+
+	var (
+		tokens = []Token{}
+		group  = matches[1]
+	)
+
+	for group != "" {
+		_, size := utf8.DecodeRuneInString(group)
+
+		var (
+			name   = "-" + group[:size]
+			option = findOption(parser.Options, name)
+		)
+
+		if option == nil {
+			if len(tokens) == 0 {
+				scanner.Tail = tail
+
+				return nil, nil
+			}
+
+			return nil, ErrUnknownOption{
+				Name: name,
+			}
+		}
+
+		group = group[size:]
+
+		token := &TokenOption{
+			Name: name,
+		}
+
+		if option.HasArgument() {
+			scanner.Tail = group
+
+			// Value is attached as is, like in -ofile, unless it can be read
+			// as more options too, then only explicit -o=file is accepted.
+			if scanner.Match(MatcherOptionValueSeparator) == nil &&
+				parser.isShortOptionsGroup(group) {
+				return nil, ErrAmbiguousOptionGroup{
+					Group: tail,
+					Name:  name,
+					Value: group,
+				}
+			}
+
+			token.Value = scanner.Tail
+
+			group = ""
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	scanner.Tail = ""
+
+	return tokens, nil
+}
+
+// isShortOptionsGroup checks whether group consists of known short options,
+// the last of which may take the rest of group as value.
+func (parser *ArgumentsParser) isShortOptionsGroup(group string) bool {
+	if group == "" {
+		return false
+	}
+
+	for _, char := range group {
+		option := findOption(parser.Options, "-"+string(char))
+		if option == nil {
+			return false
+		}
+
+		if option.HasArgument() {
+			return true
+		}
+	}
+
+	return true
+}
+
 func (parser *ArgumentsParser) parseTokenOption(
 	scanner *Scanner,
 ) Token {
 	matches := scanner.Match(MatcherOptionName)
 	if matches != nil {
-		token := TokenOption{
+		token := &TokenOption{
 			Name: matches[1],
 		}
 
 		scanner.Match(MatcherOptionValueSeparator)
 
-		matches = scanner.Match(MatcherAny)
-		if matches != nil {
-			token.Value = matches[0]
-		}
+		token.Value = scanner.Tail
+
+		scanner.Tail = ""
 
 		return token
 	}
@@ -53,26 +193,33 @@ func (parser *ArgumentsParser) parseTokenOption(
 func (parser *ArgumentsParser) parseTokenValue(
 	scanner *Scanner,
 ) Token {
-	matches := scanner.Match(MatcherAny)
-	if matches != nil {
-		return TokenPositionalArgument{
-			Value: matches[0],
-		}
+	token := &TokenPositionalArgument{
+		Value: scanner.Tail,
 	}
 
-	return nil
+	scanner.Tail = ""
+
+	return token
 }
 
-func (parser *ArgumentsParser) parseToken(scanner *Scanner) Token {
-	option := parser.parseTokenOption(scanner)
-	if option != nil {
-		return option
+func (parser *ArgumentsParser) parseTokens(scanner *Scanner) ([]Token, error) {
+	if scanner.Match(MatcherOptionsEnd) != nil {
+		return []Token{&TokenOptionsEnd{}}, nil
 	}
 
-	value := parser.parseTokenValue(scanner)
-	if value != nil {
-		return value
+	options, err := parser.parseTokensShortOptions(scanner)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if options != nil {
+		return options, nil
+	}
+
+	option := parser.parseTokenOption(scanner)
+	if option != nil {
+		return []Token{option}, nil
+	}
+
+	return []Token{parser.parseTokenValue(scanner)}, nil
 }
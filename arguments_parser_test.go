@@ -11,7 +11,7 @@ func Test_ArgumentsParser_ParsesArgumentsWithSingleOption(t *testing.T) {
 
 	expected := &Arguments{
 		Grammar: Grammar{
-			TokenOption{Name: "--help"},
+			&TokenOption{Name: "--help"},
 		},
 	}
 
@@ -28,7 +28,7 @@ func Test_ArgumentsParser_ParsesOptionWithValue(t *testing.T) {
 
 	expected := &Arguments{
 		Grammar: Grammar{
-			TokenOption{Name: "--data", Value: "value"},
+			&TokenOption{Name: "--data", Value: "value"},
 		},
 	}
 
@@ -45,9 +45,9 @@ func Test_ArgumentsParser_ParsesOptionWithSeparatedValue(t *testing.T) {
 
 	expected := &Arguments{
 		Grammar: Grammar{
-			TokenOption{Name: "--data"},
-			TokenSeparator{},
-			TokenPositionalArgument{Value: `value`},
+			&TokenOption{Name: "--data"},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `value`},
 		},
 	}
 
@@ -58,3 +58,193 @@ func Test_ArgumentsParser_ParsesOptionWithSeparatedValue(t *testing.T) {
 	test.NoError(err)
 	test.EqualValues(expected, actual)
 }
+
+func Test_ArgumentsParser_SplitsStackedShortOptions(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &ArgumentsParser{
+		Options: []Option{
+			{Names: []string{`-x`}},
+			{Names: []string{`-z`}},
+			{Names: []string{`-v`, `--verbose`}},
+			{Names: []string{`-f`, `--file`}, Value: `<file>`},
+		},
+	}
+
+	expected := &Arguments{
+		Grammar: Grammar{
+			&TokenOption{Name: "-x"},
+			&TokenOption{Name: "-z"},
+			&TokenOption{Name: "-v"},
+			&TokenOption{Name: "-f"},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `file.tgz`},
+		},
+	}
+
+	actual, err := parser.Parse([]string{`-xzvf`, `file.tgz`})
+
+	test.NoError(err)
+	test.EqualValues(expected, actual)
+}
+
+func Test_ArgumentsParser_ParsesAttachedShortOptionValue(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &ArgumentsParser{
+		Options: []Option{
+			{Names: []string{`-v`}},
+			{Names: []string{`-o`}, Value: `<file>`},
+		},
+	}
+
+	expected := &Arguments{
+		Grammar: Grammar{
+			&TokenOption{Name: "-v"},
+			&TokenOption{Name: "-o", Value: "file"},
+			&TokenSeparator{},
+			&TokenOption{Name: "-o", Value: "-v"},
+		},
+	}
+
+	actual, err := parser.Parse([]string{`-vofile`, `-o-v`})
+
+	test.NoError(err)
+	test.EqualValues(expected, actual)
+}
+
+func Test_ArgumentsParser_ReturnsErrorOnUnknownStackedShortOption(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &ArgumentsParser{
+		Options: []Option{
+			{Names: []string{`-v`}},
+		},
+	}
+
+	actual, err := parser.Parse([]string{`x`, `-vq`})
+
+	test.Nil(actual)
+	test.Equal(ErrUnknownOption{Index: 1, Name: `-q`}, err)
+}
+
+func Test_ArgumentsParser_ReturnsErrorOnAmbiguousShortOptions(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &ArgumentsParser{
+		Options: []Option{
+			{Names: []string{`-o`}, Value: `<file>`},
+			{Names: []string{`-f`}},
+			{Names: []string{`-i`}},
+			{Names: []string{`-l`}},
+			{Names: []string{`-e`}},
+		},
+	}
+
+	actual, err := parser.Parse([]string{`x`, `-ofile`})
+
+	test.Nil(actual)
+	test.Equal(ErrAmbiguousOptionGroup{
+		Index: 1,
+		Group: `-ofile`,
+		Name:  `-o`,
+		Value: `file`,
+	}, err)
+	test.EqualError(
+		err,
+		`option group -ofile is ambiguous: "file" may be value of -o `+
+			`or options, use -o=file to pass value`,
+	)
+
+	actual, err = parser.Parse([]string{`-o=file`, `-ofix`})
+
+	test.NoError(err)
+	test.EqualValues(&Arguments{
+		Grammar: Grammar{
+			&TokenOption{Name: "-o", Value: "file"},
+			&TokenSeparator{},
+			&TokenOption{Name: "-o", Value: "fix"},
+		},
+	}, actual)
+}
+
+func Test_ArgumentsParser_ParsesArgumentsAfterOptionsEndAsPositional(t *testing.T) {
+	test := assert.New(t)
+
+	expected := &Arguments{
+		Grammar: Grammar{
+			&TokenOption{Name: "-v"},
+			&TokenSeparator{},
+			&TokenOptionsEnd{},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `-x`},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `--`},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `-`},
+		},
+	}
+
+	parser := &ArgumentsParser{}
+
+	actual, err := parser.Parse([]string{`-v`, `--`, `-x`, `--`, `-`})
+
+	test.NoError(err)
+	test.EqualValues(expected, actual)
+}
+
+func Test_ArgumentsParser_StopsParsingOptionsAfterFirstPositional(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &ArgumentsParser{
+		Options: []Option{
+			{Names: []string{`-C`}, Value: `<path>`},
+			{Names: []string{`--verbose`}},
+		},
+		OptionsFirst: true,
+	}
+
+	expected := &Arguments{
+		Grammar: Grammar{
+			&TokenOption{Name: "-C"},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `dir`},
+			&TokenSeparator{},
+			&TokenOption{Name: "--verbose"},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `commit`},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `--verbose`},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: `--`},
+		},
+	}
+
+	actual, err := parser.Parse(
+		[]string{`-C`, `dir`, `--verbose`, `commit`, `--verbose`, `--`},
+	)
+
+	test.NoError(err)
+	test.EqualValues(expected, actual)
+}
+
+func Test_ArgumentsParser_KeepsEachArgumentWhole(t *testing.T) {
+	test := assert.New(t)
+
+	expected := &Arguments{
+		Grammar: Grammar{
+			&TokenPositionalArgument{Value: "x\ny"},
+			&TokenSeparator{},
+			&TokenOption{Name: "--msg", Value: "l1\nl2"},
+			&TokenSeparator{},
+			&TokenPositionalArgument{Value: ``},
+		},
+	}
+
+	parser := &ArgumentsParser{}
+
+	actual, err := parser.Parse([]string{"x\ny", "--msg=l1\nl2", ``})
+
+	test.NoError(err)
+	test.EqualValues(expected, actual)
+}
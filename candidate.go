@@ -0,0 +1,28 @@
+package docopt
+
+type CandidateKind int
+
+const (
+	CandidateKindWord CandidateKind = iota
+	CandidateKindOption
+	CandidateKindArgument
+)
+
+var candidateKindNames = map[CandidateKind]string{
+	CandidateKindWord:     "word",
+	CandidateKindOption:   "option",
+	CandidateKindArgument: "argument",
+}
+
+func (kind CandidateKind) String() string {
+	return candidateKindNames[kind]
+}
+
+// Candidate is an element which can be typed at the cursor: static word
+// to be typed as is, option name, or placeholder of positional argument or
+// option value.
+type Candidate struct {
+	Kind        CandidateKind
+	Value       string
+	Description string
+}
@@ -0,0 +1,295 @@
+package docopt
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// completionFileArgument matches placeholders which name files, like
+	// <file>, <filename>, <paths>, <input-file>, <output_dir>, <directory>
+	// or FILES, but not <profile>.
+	completionFileArgument = regexp.MustCompile(
+		`(?i)^<?([\w-]*[-_])?((file|path|dir)(name)?s?|director(y|ies))>?$`,
+	)
+	completionUnsafeName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// completionSpec is a static view of usage for completion scripts: commands
+// typed so far form a path, which determines what can be typed next.
+// Positional arguments are recorded in path as "*", repeated ones collapse
+// into single "*". Arguments which have completers make their paths
+// dynamic, so candidates are requested from the binary via __complete.
+type completionSpec struct {
+	Binary string
+
+	Paths     []string
+	Words     map[string][]string
+	Arguments map[string]bool
+	Files     map[string]bool
+	Dynamic   map[string]bool
+
+	Options    []Option
+	Completers map[string]Completer
+}
+
+// completionTransition moves path forward when Word is typed, Word "*"
+// stands for any positional argument.
+type completionTransition struct {
+	Path string
+	Word string
+}
+
+// GenerateCompletion writes completion script for the given shell, which is
+// one of bash, zsh, fish or powershell.
+func (program *Program) GenerateCompletion(shell string, writer io.Writer) error {
+	spec, err := program.getCompletionSpec()
+	if err != nil {
+		return err
+	}
+
+	var script string
+
+	switch shell {
+	case "bash":
+		script = spec.getBash()
+	case "zsh":
+		script = spec.getZsh()
+	case "fish":
+		script = spec.getFish()
+	case "powershell", "pwsh":
+		script = spec.getPowerShell()
+	default:
+		return fmt.Errorf(
+			`unsupported shell %q: expected bash, zsh, fish or powershell`,
+			shell,
+		)
+	}
+
+	_, err = io.WriteString(writer, script)
+
+	return err
+}
+
+func (program *Program) getCompletionSpec() (*completionSpec, error) {
+	spec := &completionSpec{
+		Binary:    program.Usage.Binary,
+		Words:     map[string][]string{},
+		Arguments: map[string]bool{},
+		Files:     map[string]bool{},
+		Dynamic:   map[string]bool{},
+
+		Completers: program.Completers,
+	}
+
+	trees, err := program.getTrees()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tree := range trees {
+		spec.walk(tree, []string{""})
+	}
+
+	spec.Options = (&ArgumentsMatcher{}).collectOptions(trees, program.Options)
+
+	paths := map[string]bool{}
+
+	for path := range spec.Words {
+		paths[path] = true
+	}
+
+	for path := range spec.Arguments {
+		paths[path] = true
+	}
+
+	for path := range paths {
+		spec.Paths = append(spec.Paths, path)
+	}
+
+	sort.Strings(spec.Paths)
+
+	return spec, nil
+}
+
+// walk records what can be typed after each of given paths and returns
+// paths which can be reached after the node.
+func (spec *completionSpec) walk(node Node, paths []string) []string {
+	switch node := node.(type) {
+	case *NodeRequired:
+		for _, child := range node.Children {
+			paths = spec.walk(child, paths)
+		}
+
+		return paths
+
+	case *NodeOptional:
+		return getUniquePaths(
+			paths,
+			spec.walk(&NodeRequired{Children: node.Children}, paths),
+		)
+
+	case *NodeEither:
+		reached := []string{}
+
+		for _, child := range node.Children {
+			reached = getUniquePaths(reached, spec.walk(child, paths))
+		}
+
+		return reached
+
+	case *NodeOneOrMore:
+		var (
+			once  = spec.walk(&NodeRequired{Children: node.Children}, paths)
+			twice = spec.walk(&NodeRequired{Children: node.Children}, once)
+		)
+
+		return getUniquePaths(once, twice)
+
+	case *NodeCommand:
+		reached := []string{}
+
+		for _, path := range paths {
+			spec.Words[path] = getUniquePaths(spec.Words[path], []string{node.Name})
+
+			reached = getUniquePaths(
+				reached,
+				[]string{strings.TrimSpace(path + " " + node.Name)},
+			)
+		}
+
+		return reached
+
+	case *NodeArgument:
+		reached := []string{}
+
+		for _, path := range paths {
+			spec.Arguments[path] = true
+
+			if completionFileArgument.MatchString(node.Name) {
+				spec.Files[path] = true
+			}
+
+			if spec.Completers[node.Name] != nil {
+				spec.Dynamic[path] = true
+			}
+
+			if !strings.HasSuffix(path, "*") {
+				path = strings.TrimSpace(path + " *")
+			}
+
+			reached = getUniquePaths(reached, []string{path})
+		}
+
+		return reached
+	}
+
+	return paths
+}
+
+// getCompletedPaths returns paths at which there is something to offer.
+func (spec *completionSpec) getCompletedPaths() []string {
+	paths := []string{}
+
+	for _, path := range spec.Paths {
+		if len(spec.Words[path]) > 0 || spec.Files[path] || spec.Dynamic[path] {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func (spec *completionSpec) getTransitions() []completionTransition {
+	transitions := []completionTransition{}
+
+	for _, path := range spec.Paths {
+		for _, word := range spec.Words[path] {
+			transitions = append(transitions, completionTransition{
+				Path: path,
+				Word: word,
+			})
+		}
+	}
+
+	for _, path := range spec.Paths {
+		if spec.Arguments[path] && !strings.HasSuffix(path, "*") {
+			transitions = append(transitions, completionTransition{
+				Path: path,
+				Word: "*",
+			})
+		}
+	}
+
+	return transitions
+}
+
+// getValuedOptions returns names of options which take the next argument
+// as a value, so it must be skipped when path is computed.
+func (spec *completionSpec) getValuedOptions() []string {
+	names := []string{}
+
+	for _, option := range spec.Options {
+		if option.HasArgument() {
+			names = append(names, option.Names...)
+		}
+	}
+
+	return names
+}
+
+func (spec *completionSpec) isDynamic() bool {
+	if len(spec.Dynamic) > 0 {
+		return true
+	}
+
+	for _, option := range spec.Options {
+		if spec.isDynamicOption(option) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (spec *completionSpec) isDynamicOption(option Option) bool {
+	return option.HasArgument() && spec.Completers[option.Value] != nil
+}
+
+func (spec *completionSpec) getFunctionName() string {
+	return "_" + completionUnsafeName.ReplaceAllString(spec.Binary, "_") +
+		"_completion"
+}
+
+func getUniquePaths(paths []string, more []string) []string {
+	result := append([]string{}, paths...)
+
+	for _, path := range more {
+		found := false
+
+		for _, existing := range result {
+			if existing == path {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+func getCompletionDescription(option Option) string {
+	return strings.Join(strings.Fields(option.GetDescription()), " ")
+}
+
+func isFileOption(option Option) bool {
+	return completionFileArgument.MatchString(option.Value)
+}
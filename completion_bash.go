@@ -0,0 +1,186 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (spec *completionSpec) getBash() string {
+	var (
+		script   strings.Builder
+		function = spec.getFunctionName()
+	)
+
+	fmt.Fprintf(
+		&script,
+		"# bash completion for %s, generated from its usage.\n",
+		spec.Binary,
+	)
+
+	if spec.isDynamic() {
+		fmt.Fprintf(&script, "%s_dynamic() {\n", function)
+		script.WriteString("    local IFS=$'\\n'\n\n")
+		fmt.Fprintf(
+			&script,
+			"    COMPREPLY=($(\"${COMP_WORDS[0]}\" %s "+
+				"\"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null | cut -f1))\n",
+			CompleteCommand,
+		)
+		script.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&script, "%s() {\n", function)
+	script.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	script.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	script.WriteString("    local path=\"\" word i skip=\"\"\n\n")
+
+	spec.writePathLoop(&script, "path", "COMP_WORDS", "1", "COMP_CWORD")
+
+	var dynamic, files, values []string
+
+	for _, option := range spec.Options {
+		switch {
+		case spec.isDynamicOption(option):
+			dynamic = append(dynamic, option.Names...)
+		case isFileOption(option):
+			files = append(files, option.Names...)
+		case option.HasArgument():
+			values = append(values, option.Names...)
+		}
+	}
+
+	if len(dynamic) > 0 || len(files) > 0 || len(values) > 0 {
+		script.WriteString("    case \"$prev\" in\n")
+
+		if len(dynamic) > 0 {
+			fmt.Fprintf(&script, "        %s)\n", strings.Join(dynamic, "|"))
+			fmt.Fprintf(&script, "            %s_dynamic\n", function)
+			script.WriteString("            return\n")
+			script.WriteString("            ;;\n")
+		}
+
+		if len(files) > 0 {
+			fmt.Fprintf(&script, "        %s)\n", strings.Join(files, "|"))
+			script.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			script.WriteString("            return\n")
+			script.WriteString("            ;;\n")
+		}
+
+		if len(values) > 0 {
+			fmt.Fprintf(&script, "        %s)\n", strings.Join(values, "|"))
+			script.WriteString("            COMPREPLY=()\n")
+			script.WriteString("            return\n")
+			script.WriteString("            ;;\n")
+		}
+
+		script.WriteString("    esac\n\n")
+	}
+
+	names := []string{}
+
+	for _, option := range spec.Options {
+		names = append(names, option.Names...)
+	}
+
+	script.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(
+		&script,
+		"        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n",
+		quoteShell(strings.Join(names, " ")),
+	)
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n\n")
+
+	script.WriteString("    COMPREPLY=()\n\n")
+	script.WriteString("    case \"$path\" in\n")
+
+	for _, path := range spec.getCompletedPaths() {
+		fmt.Fprintf(&script, "        %s)\n", quoteShell(path))
+
+		if spec.Dynamic[path] {
+			fmt.Fprintf(&script, "            %s_dynamic\n", function)
+			script.WriteString("            ;;\n")
+
+			continue
+		}
+
+		if words := spec.Words[path]; len(words) > 0 {
+			fmt.Fprintf(
+				&script,
+				"            COMPREPLY+=($(compgen -W %s -- \"$cur\"))\n",
+				quoteShell(strings.Join(words, " ")),
+			)
+		}
+
+		if spec.Files[path] {
+			script.WriteString("            COMPREPLY+=($(compgen -f -- \"$cur\"))\n")
+		}
+
+		script.WriteString("            ;;\n")
+	}
+
+	script.WriteString("    esac\n")
+	script.WriteString("}\n\n")
+
+	fmt.Fprintf(&script, "complete -F %s %s\n", function, quoteShell(spec.Binary))
+
+	return script.String()
+}
+
+// writePathLoop writes loop which computes path of typed commands, it is
+// shared by bash and zsh which differ only in names of variables.
+func (spec *completionSpec) writePathLoop(
+	script *strings.Builder,
+	path string,
+	words string,
+	first string,
+	current string,
+) {
+	fmt.Fprintf(script, "    for ((i = %s; i < %s; i++)); do\n", first, current)
+	fmt.Fprintf(script, "        word=\"${%s[i]}\"\n\n", words)
+	script.WriteString("        if [[ -n \"$skip\" ]]; then\n")
+	script.WriteString("            skip=\"\"\n")
+	script.WriteString("            continue\n")
+	script.WriteString("        fi\n\n")
+	script.WriteString("        case \"$word\" in\n")
+
+	if valued := spec.getValuedOptions(); len(valued) > 0 {
+		fmt.Fprintf(script, "            %s)\n", strings.Join(valued, "|"))
+		script.WriteString("                skip=1\n")
+		script.WriteString("                continue\n")
+		script.WriteString("                ;;\n")
+	}
+
+	script.WriteString("            -*)\n")
+	script.WriteString("                continue\n")
+	script.WriteString("                ;;\n")
+	script.WriteString("        esac\n\n")
+	fmt.Fprintf(script, "        case \"$%s:$word\" in\n", path)
+
+	for _, transition := range spec.getTransitions() {
+		if transition.Word == "*" {
+			fmt.Fprintf(
+				script,
+				"            %s*) %s=\"${%s:+$%s }*\" ;;\n",
+				quoteShell(transition.Path+":"), path, path, path,
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(
+			script,
+			"            %s) %s=\"${%s:+$%s }$word\" ;;\n",
+			quoteShell(transition.Path+":"+transition.Word), path, path, path,
+		)
+	}
+
+	script.WriteString("        esac\n")
+	script.WriteString("    done\n\n")
+}
+
+// quoteShell quotes string for POSIX-like shells, which also suits zsh and
+// fish.
+func quoteShell(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
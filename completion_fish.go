@@ -0,0 +1,164 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (spec *completionSpec) getFish() string {
+	var (
+		script   strings.Builder
+		function = spec.getFunctionName()
+		binary   = quoteShell(spec.Binary)
+	)
+
+	fmt.Fprintf(
+		&script,
+		"# fish completion for %s, generated from its usage.\n",
+		spec.Binary,
+	)
+	fmt.Fprintf(&script, "function %s_at\n", function)
+	script.WriteString("    set -l tokens (commandline -opc)\n")
+	script.WriteString("    set -l cmdpath \"\"\n")
+	script.WriteString("    set -l skip 0\n\n")
+	script.WriteString("    for word in $tokens[2..-1]\n")
+	script.WriteString("        if test $skip = 1\n")
+	script.WriteString("            set skip 0\n")
+	script.WriteString("            continue\n")
+	script.WriteString("        end\n\n")
+	script.WriteString("        switch $word\n")
+
+	if valued := spec.getValuedOptions(); len(valued) > 0 {
+		script.WriteString("            case")
+
+		for _, name := range valued {
+			fmt.Fprintf(&script, " %s", quoteShell(name))
+		}
+
+		script.WriteString("\n")
+		script.WriteString("                set skip 1\n")
+		script.WriteString("                continue\n")
+	}
+
+	script.WriteString("            case '-'*\n")
+	script.WriteString("                continue\n")
+	script.WriteString("        end\n\n")
+	script.WriteString("        switch \"$cmdpath:$word\"\n")
+
+	for _, transition := range spec.getTransitions() {
+		if transition.Word == "*" {
+			fmt.Fprintf(
+				&script,
+				"            case %s*\n",
+				quoteShell(transition.Path+":"),
+			)
+			script.WriteString(
+				"                set cmdpath (string trim -- \"$cmdpath *\")\n",
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(
+			&script,
+			"            case %s\n",
+			quoteShell(transition.Path+":"+transition.Word),
+		)
+		script.WriteString(
+			"                set cmdpath (string trim -- \"$cmdpath $word\")\n",
+		)
+	}
+
+	script.WriteString("        end\n")
+	script.WriteString("    end\n\n")
+	script.WriteString("    test \"$cmdpath\" = \"$argv\"\n")
+	script.WriteString("end\n\n")
+
+	if spec.isDynamic() {
+		fmt.Fprintf(&script, "function %s_dynamic\n", function)
+		script.WriteString("    set -l tokens (commandline -opc)\n")
+		fmt.Fprintf(
+			&script,
+			"    $tokens[1] %s $tokens[2..-1] (commandline -ct) 2>/dev/null\n",
+			CompleteCommand,
+		)
+		script.WriteString("end\n\n")
+	}
+
+	fmt.Fprintf(&script, "complete -c %s -f\n", binary)
+
+	for _, path := range spec.getCompletedPaths() {
+		// Condition is evaluated by fish, so words of path are quoted to keep
+		// "*" from being expanded as glob.
+		condition := function + "_at"
+		for _, word := range strings.Fields(path) {
+			condition += " " + quoteFishDouble(word)
+		}
+
+		condition = quoteShell(condition)
+
+		if spec.Dynamic[path] {
+			fmt.Fprintf(
+				&script,
+				"complete -c %s -n %s -a %s\n",
+				binary, condition, quoteShell("("+function+"_dynamic)"),
+			)
+
+			continue
+		}
+
+		if words := spec.Words[path]; len(words) > 0 {
+			fmt.Fprintf(
+				&script,
+				"complete -c %s -n %s -a %s\n",
+				binary, condition, quoteShell(strings.Join(words, " ")),
+			)
+		}
+
+		if spec.Files[path] {
+			fmt.Fprintf(&script, "complete -c %s -n %s -F\n", binary, condition)
+		}
+	}
+
+	for _, option := range spec.Options {
+		fmt.Fprintf(&script, "complete -c %s", binary)
+
+		for _, name := range option.Names {
+			switch {
+			case strings.HasPrefix(name, "--"):
+				fmt.Fprintf(&script, " -l %s", quoteShell(name[2:]))
+			case len([]rune(name)) == 2:
+				fmt.Fprintf(&script, " -s %s", quoteShell(name[1:]))
+			default:
+				fmt.Fprintf(&script, " -o %s", quoteShell(name[1:]))
+			}
+		}
+
+		if option.HasArgument() {
+			script.WriteString(" -r")
+
+			switch {
+			case spec.isDynamicOption(option):
+				fmt.Fprintf(&script, " -a %s", quoteShell("("+function+"_dynamic)"))
+			case isFileOption(option):
+				script.WriteString(" -F")
+			}
+		}
+
+		if description := getCompletionDescription(option); description != "" {
+			fmt.Fprintf(&script, " -d %s", quoteShell(description))
+		}
+
+		script.WriteString("\n")
+	}
+
+	return script.String()
+}
+
+func quoteFishDouble(text string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+	).Replace(text) + `"`
+}
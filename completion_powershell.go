@@ -0,0 +1,178 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (spec *completionSpec) getPowerShell() string {
+	var script strings.Builder
+
+	fmt.Fprintf(
+		&script,
+		"# PowerShell completion for %s, generated from its usage.\n",
+		spec.Binary,
+	)
+	fmt.Fprintf(
+		&script,
+		"Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n",
+		quotePowerShell(spec.Binary),
+	)
+	script.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	script.WriteString("    $words = @($commandAst.CommandElements |\n")
+	script.WriteString("        Select-Object -Skip 1 |\n")
+	script.WriteString("        Where-Object { $_.Extent.EndOffset -lt $cursorPosition } |\n")
+	script.WriteString("        ForEach-Object { $_.ToString() })\n\n")
+	script.WriteString("    $transitions = @(\n")
+
+	for _, transition := range spec.getTransitions() {
+		fmt.Fprintf(
+			&script,
+			"        %s\n",
+			quotePowerShell(transition.Path+":"+transition.Word),
+		)
+	}
+
+	script.WriteString("    )\n\n")
+	script.WriteString("    $valued = @(\n")
+
+	for _, name := range spec.getValuedOptions() {
+		fmt.Fprintf(&script, "        %s\n", quotePowerShell(name))
+	}
+
+	script.WriteString("    )\n\n")
+	script.WriteString("    $path = ''\n")
+	script.WriteString("    $skip = $false\n\n")
+	script.WriteString("    foreach ($word in $words) {\n")
+	script.WriteString("        if ($skip) {\n")
+	script.WriteString("            $skip = $false\n")
+	script.WriteString("        } elseif ($valued -contains $word) {\n")
+	script.WriteString("            $skip = $true\n")
+	script.WriteString("        } elseif ($word -like '-*') {\n")
+	script.WriteString("        } elseif ($transitions -contains \"${path}:$word\") {\n")
+	script.WriteString("            $path = \"$path $word\".Trim()\n")
+	script.WriteString("        } elseif ($transitions -contains \"${path}:*\") {\n")
+	script.WriteString("            $path = \"$path *\".Trim()\n")
+	script.WriteString("        }\n")
+	script.WriteString("    }\n\n")
+	script.WriteString("    $results = @()\n\n")
+
+	if spec.isDynamic() {
+		spec.writePowerShellDynamic(&script)
+
+		script.WriteString("    if ($dynamicValued -contains $previous) {\n")
+		script.WriteString("        $results = @(& $dynamic)\n")
+		script.WriteString("    } elseif ($wordToComplete -like '-*') {\n")
+	} else {
+		script.WriteString("    if ($wordToComplete -like '-*') {\n")
+	}
+
+	script.WriteString("        $results = @(\n")
+
+	for _, option := range spec.Options {
+		description := getCompletionDescription(option)
+		if description == "" {
+			description = option.GetName()
+		}
+
+		for _, name := range option.Names {
+			fmt.Fprintf(
+				&script,
+				"            [System.Management.Automation.CompletionResult]::new("+
+					"%s, %s, 'ParameterName', %s)\n",
+				quotePowerShell(name),
+				quotePowerShell(name),
+				quotePowerShell(description),
+			)
+		}
+	}
+
+	script.WriteString("        )\n")
+	script.WriteString("    } else {\n")
+	script.WriteString("        switch ($path) {\n")
+
+	for _, path := range spec.getCompletedPaths() {
+		fmt.Fprintf(&script, "            %s {\n", quotePowerShell(path))
+
+		if spec.Dynamic[path] {
+			script.WriteString("                $results += @(& $dynamic)\n")
+			script.WriteString("            }\n")
+
+			continue
+		}
+
+		for _, word := range spec.Words[path] {
+			fmt.Fprintf(
+				&script,
+				"                $results += [System.Management.Automation.CompletionResult]::new("+
+					"%s, %s, 'ParameterValue', %s)\n",
+				quotePowerShell(word),
+				quotePowerShell(word),
+				quotePowerShell(word),
+			)
+		}
+
+		if spec.Files[path] {
+			script.WriteString("                $results += Get-ChildItem -Path \"$wordToComplete*\" |\n")
+			script.WriteString("                    ForEach-Object {\n")
+			script.WriteString("                        [System.Management.Automation.CompletionResult]::new(\n")
+			script.WriteString("                            $_.Name, $_.Name, 'ProviderItem', $_.Name)\n")
+			script.WriteString("                    }\n")
+		}
+
+		script.WriteString("            }\n")
+	}
+
+	script.WriteString("        }\n")
+	script.WriteString("    }\n\n")
+	script.WriteString("    $results | Where-Object { $_.CompletionText -like \"$wordToComplete*\" }\n")
+	script.WriteString("}\n")
+
+	return script.String()
+}
+
+// writePowerShellDynamic writes script block which requests candidates
+// from the binary. Windows PowerShell and PowerShell before 7.3 drop empty
+// arguments of native commands, so empty word is passed quoted.
+func (spec *completionSpec) writePowerShellDynamic(script *strings.Builder) {
+	script.WriteString("    $dynamicValued = @(\n")
+
+	for _, option := range spec.Options {
+		if !spec.isDynamicOption(option) {
+			continue
+		}
+
+		for _, name := range option.Names {
+			fmt.Fprintf(script, "        %s\n", quotePowerShell(name))
+		}
+	}
+
+	script.WriteString("    )\n\n")
+	script.WriteString("    $previous = ''\n\n")
+	script.WriteString("    if ($words.Count -gt 0) {\n")
+	script.WriteString("        $previous = $words[-1]\n")
+	script.WriteString("    }\n\n")
+	script.WriteString("    $dynamic = {\n")
+	script.WriteString("        $current = $wordToComplete\n\n")
+	script.WriteString("        if (-not $current -and $PSVersionTable.PSVersion -lt [version]'7.3') {\n")
+	script.WriteString("            $current = '\"\"'\n")
+	script.WriteString("        }\n\n")
+	fmt.Fprintf(
+		script,
+		"        & $commandAst.CommandElements[0].ToString() %s @words $current 2>$null |\n",
+		CompleteCommand,
+	)
+	script.WriteString("            ForEach-Object {\n")
+	script.WriteString("                $value, $description = $_ -split \"`t\", 2\n\n")
+	script.WriteString("                if (-not $description) {\n")
+	script.WriteString("                    $description = $value\n")
+	script.WriteString("                }\n\n")
+	script.WriteString("                [System.Management.Automation.CompletionResult]::new(\n")
+	script.WriteString("                    $value, $value, 'ParameterValue', $description)\n")
+	script.WriteString("            }\n")
+	script.WriteString("    }\n\n")
+}
+
+func quotePowerShell(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
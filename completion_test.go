@@ -0,0 +1,192 @@
+package docopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCompletionDoc = `Usage:
+  naval ship new <name>
+  naval ship <name> move <x> <y> [--speed=<kn>]
+  naval load <file>... [-o <path>]
+
+Options:
+  --speed=<kn>  Speed in knots [default: 10].
+  -o <path>     Output path.
+`
+
+func Test_CompletionSpec_TracksCommandsAndArguments(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	spec, err := program.getCompletionSpec()
+	test.NoError(err)
+
+	test.Equal([]string{`ship`, `load`}, spec.Words[``])
+	test.Equal([]string{`new`}, spec.Words[`ship`])
+	test.Equal([]string{`move`}, spec.Words[`ship *`])
+	test.True(spec.Files[`load`])
+	test.True(spec.Files[`load *`])
+	test.False(spec.Files[`ship`])
+	test.Equal([]string{`--speed`, `-o`}, spec.getValuedOptions())
+	test.Equal([]string{``, `load`, `load *`, `ship`, `ship *`},
+		spec.getCompletedPaths())
+}
+
+func Test_CompletionSpec_CompletesFilesForFilePlaceholdersOnly(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  app read <file>
+  app write <output-file>
+  app copy DIR
+  app open <filename>
+  app add [<paths>...]
+  app cd <directory>
+  app cat FILES...
+  app use <profile>
+  app pick <filter>
+`)
+	test.NoError(err)
+
+	spec, err := program.getCompletionSpec()
+	test.NoError(err)
+
+	test.True(spec.Files[`read`])
+	test.True(spec.Files[`write`])
+	test.True(spec.Files[`copy`])
+	test.True(spec.Files[`open`])
+	test.True(spec.Files[`add`])
+	test.True(spec.Files[`cd`])
+	test.True(spec.Files[`cat`])
+	test.False(spec.Files[`use`])
+	test.False(spec.Files[`pick`])
+}
+
+func Test_Program_GeneratesBashCompletion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	test.NoError(program.GenerateCompletion(`bash`, output))
+
+	script := output.String()
+
+	test.Contains(script, `'ship:new') path="${path:+$path }$word" ;;`)
+	test.Contains(script, `'ship:'*) path="${path:+$path }*" ;;`)
+	test.Contains(script, `--speed|-o)`)
+	test.Contains(script, `compgen -W 'ship load' -- "$cur"`)
+	test.Contains(script, `compgen -W '--speed -o' -- "$cur"`)
+	test.Contains(script, `complete -F _naval_completion 'naval'`)
+}
+
+func Test_Program_GeneratesZshCompletion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	test.NoError(program.GenerateCompletion(`zsh`, output))
+
+	script := output.String()
+
+	test.Contains(script, "#compdef naval\n")
+	test.Contains(script, `'ship *:move') cmdpath="${cmdpath:+$cmdpath }$word" ;;`)
+	test.Contains(script, `'--speed:Speed in knots [default: 10].'`)
+	test.Contains(script, `compdef _naval_completion naval`)
+}
+
+func Test_Program_GeneratesFishCompletion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	test.NoError(program.GenerateCompletion(`fish`, output))
+
+	script := output.String()
+
+	test.Contains(script, `case 'load:'*`)
+	test.Contains(script, `complete -c 'naval' -n '_naval_completion_at "ship"' -a 'new'`)
+	test.Contains(script, `complete -c 'naval' -n '_naval_completion_at "load"' -F`)
+	test.Contains(script, `complete -c 'naval' -n '_naval_completion_at "ship" "*"' -a 'move'`)
+	test.Contains(script, `complete -c 'naval' -s 'o' -r -F -d 'Output path.'`)
+}
+
+func Test_Program_GeneratesPowerShellCompletion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	var (
+		script = &bytes.Buffer{}
+		pwsh   = &bytes.Buffer{}
+	)
+
+	test.NoError(program.GenerateCompletion(`powershell`, script))
+	test.NoError(program.GenerateCompletion(`pwsh`, pwsh))
+
+	test.Contains(script.String(), `Register-ArgumentCompleter -Native -CommandName 'naval'`)
+	test.Contains(script.String(), `'ship *:move'`)
+	test.Contains(script.String(), `::new('new', 'new', 'ParameterValue', 'new')`)
+	test.Equal(script.String(), pwsh.String())
+}
+
+func Test_Program_RejectsUnsupportedShell(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	err = program.GenerateCompletion(`tcsh`, &bytes.Buffer{})
+
+	test.EqualError(
+		err,
+		`unsupported shell "tcsh": expected bash, zsh, fish or powershell`,
+	)
+}
+
+func Test_Program_GeneratesDynamicCompletion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testCompletionDoc)
+	test.NoError(err)
+
+	program.Completers = map[string]Completer{
+		`<name>`: func(argv []string, prefix string) []Candidate {
+			return nil
+		},
+	}
+
+	output := &bytes.Buffer{}
+
+	test.NoError(program.GenerateCompletion(`bash`, output))
+	test.Contains(output.String(), `"${COMP_WORDS[0]}" __complete`)
+	test.Contains(output.String(), "        'ship')\n"+
+		"            _naval_completion_dynamic\n")
+
+	output.Reset()
+
+	test.NoError(program.GenerateCompletion(`fish`, output))
+	test.Contains(output.String(), `$tokens[1] __complete $tokens[2..-1]`)
+	test.Contains(output.String(), `-n '_naval_completion_at "ship"' -a '(_naval_completion_dynamic)'`)
+
+	output.Reset()
+
+	program.Completers = nil
+
+	test.NoError(program.GenerateCompletion(`bash`, output))
+	test.NotContains(output.String(), `__complete`)
+}
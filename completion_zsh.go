@@ -0,0 +1,115 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (spec *completionSpec) getZsh() string {
+	var (
+		script   strings.Builder
+		function = spec.getFunctionName()
+	)
+
+	fmt.Fprintf(&script, "#compdef %s\n\n", spec.Binary)
+	fmt.Fprintf(
+		&script,
+		"# zsh completion for %s, generated from its usage.\n",
+		spec.Binary,
+	)
+
+	if spec.isDynamic() {
+		fmt.Fprintf(&script, "%s_dynamic() {\n", function)
+		script.WriteString("    local -a candidates\n\n")
+		fmt.Fprintf(
+			&script,
+			"    candidates=(${(f)\"$(\"${words[1]}\" %s "+
+				"\"${(@)words[2,CURRENT]}\" 2>/dev/null)\"})\n",
+			CompleteCommand,
+		)
+		script.WriteString("    candidates=(\"${(@)candidates%%$'\\t'*}\")\n")
+		script.WriteString("    compadd -a candidates\n")
+		script.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&script, "%s() {\n", function)
+	script.WriteString("    local cmdpath=\"\" word i skip=\"\"\n")
+	script.WriteString("    local -a commands options\n\n")
+
+	spec.writePathLoop(&script, "cmdpath", "words", "2", "CURRENT")
+
+	script.WriteString("    case \"${words[CURRENT-1]}\" in\n")
+
+	for _, option := range spec.Options {
+		if !option.HasArgument() {
+			continue
+		}
+
+		fmt.Fprintf(&script, "        %s)\n", strings.Join(option.Names, "|"))
+
+		switch {
+		case spec.isDynamicOption(option):
+			fmt.Fprintf(&script, "            %s_dynamic\n", function)
+		case isFileOption(option):
+			script.WriteString("            _files\n")
+		}
+
+		script.WriteString("            return\n")
+		script.WriteString("            ;;\n")
+	}
+
+	script.WriteString("    esac\n\n")
+
+	script.WriteString("    if [[ \"${words[CURRENT]}\" == -* ]]; then\n")
+	script.WriteString("        options=(\n")
+
+	for _, option := range spec.Options {
+		for _, name := range option.Names {
+			fmt.Fprintf(
+				&script,
+				"            %s\n",
+				quoteShell(name+":"+getCompletionDescription(option)),
+			)
+		}
+	}
+
+	script.WriteString("        )\n\n")
+	script.WriteString("        _describe 'option' options\n")
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n\n")
+
+	script.WriteString("    case \"$cmdpath\" in\n")
+
+	for _, path := range spec.getCompletedPaths() {
+		fmt.Fprintf(&script, "        %s)\n", quoteShell(path))
+
+		if spec.Dynamic[path] {
+			fmt.Fprintf(&script, "            %s_dynamic\n", function)
+			script.WriteString("            ;;\n")
+
+			continue
+		}
+
+		if words := spec.Words[path]; len(words) > 0 {
+			fmt.Fprintf(
+				&script,
+				"            commands=(%s)\n",
+				strings.Join(words, " "),
+			)
+			script.WriteString("            compadd -a commands\n")
+		}
+
+		if spec.Files[path] {
+			script.WriteString("            _files\n")
+		}
+
+		script.WriteString("            ;;\n")
+	}
+
+	script.WriteString("    esac\n")
+	script.WriteString("}\n\n")
+
+	fmt.Fprintf(&script, "compdef %s %s\n", function, spec.Binary)
+
+	return script.String()
+}
@@ -0,0 +1,73 @@
+package docopt
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	TabWidth = 8
+)
+
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+func getRuneWidth(char rune) int {
+	if unicode.In(char, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+
+	for _, wide := range wideRanges {
+		if char >= wide[0] && char <= wide[1] {
+			return 2
+		}
+	}
+
+	return 1
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, assuming
+// text is printed starting at the given display column. It returns expanded
+// text and display column after it.
+func expandTabs(text string, column int) (string, int) {
+	var expanded strings.Builder
+
+	for _, char := range text {
+		if char == '\t' {
+			stop := TabWidth - column%TabWidth
+
+			expanded.WriteString(strings.Repeat(" ", stop))
+
+			column += stop
+
+			continue
+		}
+
+		expanded.WriteRune(char)
+
+		column += getRuneWidth(char)
+	}
+
+	return expanded.String(), column
+}
+
+func getDisplayWidth(text string) int {
+	_, width := expandTabs(text, 0)
+
+	return width
+}
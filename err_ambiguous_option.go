@@ -0,0 +1,38 @@
+package docopt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type ErrAmbiguousOption struct {
+	Index      int
+	Name       string
+	Candidates []Option
+}
+
+func (err ErrAmbiguousOption) Error() string {
+	names := []string{}
+
+	for _, candidate := range err.Candidates {
+		for _, name := range candidate.Names {
+			if strings.HasPrefix(name, err.Name) {
+				names = append(names, name)
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return fmt.Sprintf(
+		`option %s is ambiguous: %s`,
+		err.Name, strings.Join(names, ", "),
+	)
+}
+
+func (err ErrAmbiguousOption) GetIndex() int {
+	return err.Index
+}
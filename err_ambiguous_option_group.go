@@ -0,0 +1,25 @@
+package docopt
+
+import "fmt"
+
+type ErrAmbiguousOptionGroup struct {
+	Index int
+	Group string
+
+	// Name is the short option taking value, while Value is the rest of
+	// the group, which is also a group of known short options.
+	Name  string
+	Value string
+}
+
+func (err ErrAmbiguousOptionGroup) Error() string {
+	return fmt.Sprintf(
+		`option group %s is ambiguous: %q may be value of %s or options, `+
+			`use %s=%s to pass value`,
+		err.Group, err.Value, err.Name, err.Name, err.Value,
+	)
+}
+
+func (err ErrAmbiguousOptionGroup) GetIndex() int {
+	return err.Index
+}
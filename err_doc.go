@@ -0,0 +1,9 @@
+package docopt
+
+// ErrDoc is implemented by errors in the doc itself, which can't be fixed by
+// user of the program and usually should be treated as a programming error.
+type ErrDoc interface {
+	error
+
+	GetSource() Span
+}
@@ -0,0 +1,13 @@
+package docopt
+
+import "fmt"
+
+// ErrExited is returned by Parse after help or version was printed, if the
+// exit function returned instead of terminating the process.
+type ErrExited struct {
+	Code int
+}
+
+func (err ErrExited) Error() string {
+	return fmt.Sprintf(`exited with code %d`, err.Code)
+}
@@ -0,0 +1,11 @@
+package docopt
+
+// ErrInput is implemented by errors caused by arguments given to the
+// program, which should be reported to user along with usage.
+type ErrInput interface {
+	error
+
+	// GetIndex returns index of offending argument in argv, or len(argv) if
+	// something is missing at the end.
+	GetIndex() int
+}
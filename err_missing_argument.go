@@ -0,0 +1,32 @@
+package docopt
+
+import "fmt"
+
+type ErrMissingArgument struct {
+	Index int
+	Name  string
+
+	// Command is a path of commands user has typed before the missing
+	// argument, like "remote add".
+	Command string
+
+	// Usage holds usage lines relevant to the typed command, it's filled by
+	// Program.Parse and printed by Program.FormatError.
+	Usage []string
+}
+
+func (err ErrMissingArgument) Error() string {
+	if err.Command != "" {
+		return fmt.Sprintf(`%s: missing required %s`, err.Command, err.Name)
+	}
+
+	return fmt.Sprintf(`missing required %s`, err.Name)
+}
+
+func (err ErrMissingArgument) GetIndex() int {
+	return err.Index
+}
+
+func (err ErrMissingArgument) GetUsage() []string {
+	return err.Usage
+}
@@ -0,0 +1,37 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ErrNoVariantMatched struct {
+	Args []string
+
+	// Index points at the first argument none of usage variants was able
+	// to consume.
+	Index int
+
+	// Suggestions are commands expected at Index which are close to the
+	// given argument.
+	Suggestions []string
+
+	// Usage holds usage lines relevant to the typed command, it's filled by
+	// Program.Parse and printed by Program.FormatError.
+	Usage []string
+}
+
+func (err ErrNoVariantMatched) Error() string {
+	return fmt.Sprintf(
+		`no usage variant matches arguments: %q%s`,
+		strings.Join(err.Args, " "), formatSuggestions(err.Suggestions),
+	)
+}
+
+func (err ErrNoVariantMatched) GetIndex() int {
+	return err.Index
+}
+
+func (err ErrNoVariantMatched) GetUsage() []string {
+	return err.Usage
+}
@@ -0,0 +1,17 @@
+package docopt
+
+import "fmt"
+
+type ErrOptionRequiresValue struct {
+	Index  int
+	Name   string
+	Option Option
+}
+
+func (err ErrOptionRequiresValue) Error() string {
+	return fmt.Sprintf(`option %s requires argument`, err.Name)
+}
+
+func (err ErrOptionRequiresValue) GetIndex() int {
+	return err.Index
+}
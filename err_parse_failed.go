@@ -1,6 +1,10 @@
 package docopt
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 const (
 	CursorSign = `→`
@@ -10,11 +14,61 @@ type ErrParseFailed struct {
 	Message string
 	Line    string
 	Tail    string
+	Source  Span
 }
 
 func (err ErrParseFailed) Error() string {
+	message := err.Message
+
+	if err.Source.Line > 0 {
+		message = err.Source.String() + ": " + message
+	}
+
+	if err.Line == "" && err.Tail == "" {
+		return message
+	}
+
 	cursor := len(err.Line) - len(err.Tail)
 	line := err.Line[:cursor] + CursorSign + err.Line[cursor:]
 
-	return fmt.Sprintf(`%s: %q`, err.Message, line)
+	return fmt.Sprintf(`%s: %q`, message, line)
+}
+
+// Excerpt renders the failed line prefixed with its number and a line with
+// carets under the offending text. Carets are aligned by display width, so
+// tabs and wide characters before them don't shift the cursor.
+func (err ErrParseFailed) Excerpt() string {
+	var (
+		number = strconv.Itoa(err.Source.Line)
+		start  = len(err.Line) - len(err.Tail)
+		end    = err.Source.End
+	)
+
+	if end < start || end > len(err.Line) {
+		end = start
+	}
+
+	var (
+		_, indent = expandTabs(err.Line[:start], 0)
+		_, marked = expandTabs(err.Line[start:end], indent)
+		line, _   = expandTabs(err.Line, 0)
+		width     = marked - indent
+	)
+
+	if width < 1 {
+		width = 1
+	}
+
+	return fmt.Sprintf(
+		"%s | %s\n%s | %s%s",
+		number,
+		line,
+		strings.Repeat(" ", len(number)),
+		strings.Repeat(" ", indent),
+		strings.Repeat("^", width),
+	)
+}
+
+func (err ErrParseFailed) GetSource() Span {
+	return err.Source
 }
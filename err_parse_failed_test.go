@@ -0,0 +1,50 @@
+package docopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ErrParseFailed_AlignsCaretByDisplayWidth(t *testing.T) {
+	test := assert.New(t)
+
+	line := "  工具 同步 (<文件>]"
+
+	err := ErrParseFailed{
+		Message: `group is not closed`,
+		Line:    line,
+		Tail:    line[len("  工具 同步 "):],
+		Source:  Span{Line: 7, Start: len("  工具 同步 "), End: len("  工具 同步 (")},
+	}
+
+	test.Equal(
+		"7 |   工具 同步 (<文件>]\n"+
+			"  |             ^",
+		err.Excerpt(),
+	)
+
+	test.Equal(
+		`7:17: group is not closed: "  工具 同步 →(<文件>]"`,
+		err.Error(),
+	)
+}
+
+func Test_ErrParseFailed_ExpandsTabsInExcerpt(t *testing.T) {
+	test := assert.New(t)
+
+	line := "\tprog\t<file> )"
+
+	err := ErrParseFailed{
+		Message: `unbalanced group end`,
+		Line:    line,
+		Tail:    line[len("\tprog\t<file> "):],
+		Source:  Span{Line: 2, Start: 13, End: 14},
+	}
+
+	test.Equal(
+		"2 |         prog    <file> )\n"+
+			"  |                        ^",
+		err.Excerpt(),
+	)
+}
@@ -0,0 +1,55 @@
+package docopt
+
+import "fmt"
+
+// ErrUnexpectedArgument is returned when argument is left over after the
+// best matching usage variant. If Option is set, either Value was given to
+// the option which doesn't take an argument, or, if Value is empty, the
+// option itself is not allowed by any usage variant.
+type ErrUnexpectedArgument struct {
+	Index       int
+	Value       string
+	Option      *Option
+	Suggestions []string
+
+	// After is the last argument or command which was matched before the
+	// unexpected one.
+	After string
+
+	// Usage holds usage lines relevant to the typed command, it's filled by
+	// Program.Parse and printed by Program.FormatError.
+	Usage []string
+}
+
+func (err ErrUnexpectedArgument) Error() string {
+	switch {
+	case err.Option != nil && err.Value != "":
+		return fmt.Sprintf(
+			`option %s does not take an argument`,
+			err.Option.GetName(),
+		)
+
+	case err.Option != nil:
+		return fmt.Sprintf(`unexpected option %s`, err.Option.GetName())
+	}
+
+	if err.After != "" {
+		return fmt.Sprintf(
+			`unexpected argument %q after %s%s`,
+			err.Value, err.After, formatSuggestions(err.Suggestions),
+		)
+	}
+
+	return fmt.Sprintf(
+		`unexpected argument %q%s`,
+		err.Value, formatSuggestions(err.Suggestions),
+	)
+}
+
+func (err ErrUnexpectedArgument) GetIndex() int {
+	return err.Index
+}
+
+func (err ErrUnexpectedArgument) GetUsage() []string {
+	return err.Usage
+}
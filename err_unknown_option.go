@@ -0,0 +1,20 @@
+package docopt
+
+import "fmt"
+
+type ErrUnknownOption struct {
+	Index       int
+	Name        string
+	Suggestions []string
+}
+
+func (err ErrUnknownOption) Error() string {
+	return fmt.Sprintf(
+		`unknown option %s%s`,
+		err.Name, formatSuggestions(err.Suggestions),
+	)
+}
+
+func (err ErrUnknownOption) GetIndex() int {
+	return err.Index
+}
@@ -26,10 +26,10 @@ func (grammar Grammar) Balance() error {
 		}
 
 		if len(pairs) == 0 {
-			return fmt.Errorf(
-				"unbalanced group end at position %d",
-				index,
-			)
+			return ErrParseFailed{
+				Message: `unbalanced group end`,
+				Source:  token.Span(),
+			}
 		}
 
 		var (
@@ -42,11 +42,35 @@ func (grammar Grammar) Balance() error {
 		}
 
 		if group != nil {
+			// Zero-width group start doesn't come from the doc either, so
+			// group end from the doc which closes it has no pair.
+			if start.Span().Start == start.Span().End &&
+				group.Span().Start != group.Span().End {
+				return ErrParseFailed{
+					Message: `unbalanced group end`,
+					Source:  group.Span(),
+				}
+			}
+
 			if start.Required != group.Required {
-				return fmt.Errorf(
-					"opened group at position %d do not match %d",
-					pair, index,
-				)
+				// Zero-width group end doesn't come from the doc, it
+				// closes the whole variant, so opened group is at fault.
+				if group.Span().Start == group.Span().End {
+					return ErrParseFailed{
+						Message: `group is not closed`,
+						Source:  start.Span(),
+					}
+				}
+
+				return ErrParseFailed{
+					Message: fmt.Sprintf(
+						`%q opened at %s is closed by %q`,
+						start.getBracket(),
+						start.Span(),
+						group.getBracket(),
+					),
+					Source: group.Span(),
+				}
 			}
 
 			group.Pair = pair
@@ -62,20 +86,29 @@ func (grammar Grammar) Balance() error {
 	}
 
 	if len(pairs) > 0 {
-		return fmt.Errorf(
-			`one or more of required or optional groups is not closed`,
-		)
+		return ErrParseFailed{
+			Message: `group is not closed`,
+			Source:  grammar[pairs[len(pairs)-1]].Span(),
+		}
 	}
 
 	return nil
 }
 
+// Expand returns flat variants of the grammar without groups and branches.
+// Repeats are kept as is after the repeated atom, they get their meaning
+// in the tree.
 func (grammar *Grammar) Expand() ([]Grammar, error) {
 	err := grammar.Balance()
 	if err != nil {
 		return nil, err
 	}
 
+	err = grammar.checkRepeats()
+	if err != nil {
+		return nil, err
+	}
+
 	grammars := grammar.expandGroups()
 
 	for i, grammar := range grammars {
@@ -93,6 +126,37 @@ func (grammar *Grammar) Expand() ([]Grammar, error) {
 	return grammars, nil
 }
 
+// checkRepeats ensures that every repeat follows an atom or a group.
+func (grammar Grammar) checkRepeats() error {
+	for index, token := range grammar {
+		repeat, ok := token.(*TokenRepeat)
+		if !ok {
+			continue
+		}
+
+		valid := index > 0
+
+		if valid {
+			switch previous := grammar[index-1].(type) {
+			case *TokenGroup:
+				valid = !previous.Opened
+
+			case *TokenSeparator, *TokenBranch:
+				valid = false
+			}
+		}
+
+		if !valid {
+			return ErrParseFailed{
+				Message: `nothing to repeat`,
+				Source:  repeat.Span(),
+			}
+		}
+	}
+
+	return nil
+}
+
 func (grammar Grammar) expandGroups() []Grammar {
 	stack := []Grammar{grammar}
 
@@ -164,6 +228,68 @@ func (grammar Grammar) expandGroups() []Grammar {
 	return variants
 }
 
+func (grammar Grammar) getElementEnd(index int) int {
+	if group, ok := grammar[index].(*TokenGroup); ok && group.Opened {
+		return group.Pair + 1
+	}
+
+	return index + 1
+}
+
+func (grammar Grammar) getAlternatives(index int) [][2]int {
+	var (
+		group = grammar[index].(*TokenGroup)
+		begin = index + 1
+	)
+
+	for position := begin; position < group.Pair; {
+		branch, ok := grammar[position].(*TokenBranch)
+		if !ok {
+			position = grammar.getElementEnd(position)
+
+			continue
+		}
+
+		alternatives := [][2]int{{begin, position}}
+
+		for ok {
+			alternatives = append(
+				alternatives,
+				[2]int{position + 1, branch.Next},
+			)
+
+			position = branch.Next
+
+			branch, ok = grammar[position].(*TokenBranch)
+		}
+
+		return alternatives
+	}
+
+	return [][2]int{{begin, group.Pair}}
+}
+
+func (grammar Grammar) clone() Grammar {
+	clone := make(Grammar, len(grammar))
+
+	for index, token := range grammar {
+		switch token := token.(type) {
+		case *TokenGroup:
+			group := *token
+			clone[index] = &group
+
+		case *TokenBranch:
+			branch := *token
+			clone[index] = &branch
+
+		default:
+			clone[index] = token
+		}
+	}
+
+	return clone
+}
+
 func (grammar Grammar) cut(begin, end int) Grammar {
 	var clone = make(Grammar, len(grammar))
 
@@ -175,3 +301,179 @@ func (grammar Grammar) cut(begin, end int) Grammar {
 
 	return clone
 }
+
+func (grammar Grammar) ResolveOptionsShortcuts(options []Option) {
+	explicit := map[string]bool{}
+
+	for _, token := range grammar {
+		if token, ok := token.(*TokenOption); ok {
+			explicit[token.Name] = true
+		}
+	}
+
+	resolved := []Option{}
+
+	for _, option := range options {
+		found := false
+
+		for _, name := range option.Names {
+			if explicit[name] {
+				found = true
+			}
+		}
+
+		if !found {
+			resolved = append(resolved, option)
+		}
+	}
+
+	for _, token := range grammar {
+		if token, ok := token.(*TokenOptionsShortcut); ok {
+			token.Options = resolved
+		}
+	}
+}
+
+// getCommands returns static words the grammar starts with.
+func (grammar Grammar) getCommands() []string {
+	commands := []string{}
+
+	for index, token := range grammar {
+		switch token := token.(type) {
+		case *TokenSeparator:
+			continue
+
+		case *TokenGroup:
+			if index == 0 && token.Required {
+				continue
+			}
+
+		case *TokenStaticWord:
+			commands = append(commands, token.Name)
+
+			continue
+		}
+
+		break
+	}
+
+	return commands
+}
+
+func (grammar Grammar) getSpans() (Span, Span) {
+	if len(grammar) == 0 {
+		return Span{}, Span{}
+	}
+
+	var (
+		first = grammar[0].Span()
+		last  = grammar[len(grammar)-1].Span()
+	)
+
+	return Span{Line: first.Line, Start: first.Start, End: first.Start},
+		Span{Line: last.Line, Start: last.End, End: last.End}
+}
+
+func (grammar Grammar) Tree() (*NodeRequired, error) {
+	err := grammar.Balance()
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodeRequired{Children: grammar.getNodes(0, len(grammar))}, nil
+}
+
+func getTrees(variants []Grammar) ([]*NodeRequired, error) {
+	trees := []*NodeRequired{}
+
+	for _, variant := range variants {
+		tree, err := variant.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		trees = append(trees, tree)
+	}
+
+	return trees, nil
+}
+
+func (grammar Grammar) getNodes(begin, end int) []Node {
+	nodes := []Node{}
+
+	for index := begin; index < end; {
+		var (
+			node = grammar.getNode(index)
+			tail = grammar.getElementEnd(index)
+		)
+
+		if tail < end {
+			if _, ok := grammar[tail].(*TokenRepeat); ok {
+				node = &NodeOneOrMore{Children: []Node{node}}
+
+				tail++
+			}
+		}
+
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+
+		index = tail
+	}
+
+	return nodes
+}
+
+func (grammar Grammar) getNode(index int) Node {
+	switch token := grammar[index].(type) {
+	case *TokenGroup:
+		var (
+			alternatives = grammar.getAlternatives(index)
+			children     []Node
+		)
+
+		if len(alternatives) == 1 {
+			children = grammar.getNodes(alternatives[0][0], alternatives[0][1])
+		} else {
+			either := &NodeEither{}
+
+			for _, alternative := range alternatives {
+				either.Children = append(either.Children, &NodeRequired{
+					Children: grammar.getNodes(alternative[0], alternative[1]),
+				})
+			}
+
+			children = []Node{either}
+		}
+
+		if token.Required {
+			return &NodeRequired{Children: children}
+		}
+
+		return &NodeOptional{Children: children}
+
+	case *TokenStaticWord:
+		return &NodeCommand{Name: token.Name}
+
+	case *TokenPositionalArgument:
+		return &NodeArgument{Name: token.Value}
+
+	case *TokenOption:
+		return &NodeOption{Name: token.Name, Value: token.Value}
+
+	case *TokenSeparator:
+		return &NodeSeparator{}
+
+	case *TokenOptionsShortcut:
+		return &NodeOptionsShortcut{Options: token.Options}
+
+	case *TokenOptionsEnd:
+		return &NodeOptionsEnd{}
+
+	case *TokenStdin:
+		return &NodeStdin{}
+	}
+
+	return nil
+}
@@ -243,3 +243,170 @@ func TestGrammar_Expand_ExpandsNestedRequiredAndOptionalGroups(t *testing.T) {
 		variants,
 	)
 }
+
+func TestGrammar_ResolveOptionsShortcuts_SkipsExplicitOptions(t *testing.T) {
+	test := assert.New(t)
+
+	shortcut := &TokenOptionsShortcut{}
+
+	grammar := Grammar{
+		&TokenGroup{Opened: true},
+		shortcut,
+		&TokenGroup{Opened: false},
+		&TokenOption{Name: "-v"},
+	}
+
+	grammar.ResolveOptionsShortcuts([]Option{
+		{Names: []string{"-v", "--verbose"}},
+		{Names: []string{"-q", "--quiet"}},
+	})
+
+	test.Equal(
+		[]Option{{Names: []string{"-q", "--quiet"}}},
+		shortcut.Options,
+	)
+}
+
+func TestGrammar_Expand_KeepsRepeatAfterAtom(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenStaticWord{Name: "cat"},
+		&TokenSeparator{},
+		&TokenPositionalArgument{Value: "<file>"},
+		&TokenRepeat{},
+	}
+
+	variants, err := grammar.Expand()
+
+	test.NoError(err)
+
+	test.Equal(
+		[]Grammar{
+			{
+				&TokenStaticWord{Name: "cat"},
+				&TokenSeparator{},
+				&TokenPositionalArgument{Value: "<file>"},
+				&TokenRepeat{},
+			},
+		},
+		variants,
+	)
+}
+
+func TestGrammar_Expand_KeepsRepeatAfterGroup(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenGroup{Opened: true, Required: true},
+		&TokenOption{Name: "-v"},
+		&TokenBranch{},
+		&TokenOption{Name: "-q"},
+		&TokenGroup{Opened: false, Required: true},
+		&TokenRepeat{},
+	}
+
+	variants, err := grammar.Expand()
+
+	test.NoError(err)
+
+	test.Equal(
+		[]Grammar{
+			{&TokenOption{Name: "-v"}, &TokenRepeat{}},
+			{&TokenOption{Name: "-q"}, &TokenRepeat{}},
+		},
+		variants,
+	)
+}
+
+func TestGrammar_Expand_ReturnsErrorOnNothingToRepeat(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenRepeat{},
+	}
+
+	_, err := grammar.Expand()
+
+	test.Error(err)
+}
+
+func TestGrammar_Tree_ConvertsGroupsAndRepeats(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenGroup{Opened: true, Required: true},
+		&TokenStaticWord{Name: "a"},
+		&TokenBranch{},
+		&TokenStaticWord{Name: "b"},
+		&TokenGroup{Opened: false, Required: true},
+		&TokenSeparator{},
+		&TokenGroup{Opened: true, Required: false},
+		&TokenPositionalArgument{Value: "<x>"},
+		&TokenGroup{Opened: false, Required: false},
+		&TokenRepeat{},
+	}
+
+	tree, err := grammar.Tree()
+
+	test.NoError(err)
+
+	test.Equal(
+		&NodeRequired{
+			Children: []Node{
+				&NodeRequired{
+					Children: []Node{
+						&NodeEither{
+							Children: []Node{
+								&NodeRequired{
+									Children: []Node{&NodeCommand{Name: "a"}},
+								},
+								&NodeRequired{
+									Children: []Node{&NodeCommand{Name: "b"}},
+								},
+							},
+						},
+					},
+				},
+				&NodeSeparator{},
+				&NodeOneOrMore{
+					Children: []Node{
+						&NodeOptional{
+							Children: []Node{&NodeArgument{Name: "<x>"}},
+						},
+					},
+				},
+			},
+		},
+		tree,
+	)
+}
+
+func TestGrammar_Tree_ReturnsErrorOnUnbalancedGroups(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenGroup{Opened: true, Required: true},
+		&TokenStaticWord{Name: "a"},
+	}
+
+	_, err := grammar.Tree()
+
+	test.Error(err)
+}
+
+func TestGrammar_Balance_ReportsSpanOfUnbalancedGroupEnd(t *testing.T) {
+	test := assert.New(t)
+
+	grammar := Grammar{
+		&TokenStaticWord{Name: "a"},
+		&TokenGroup{
+			TokenSource: TokenSource{Source: Span{Line: 3, Start: 4, End: 5}},
+			Required:    true,
+		},
+	}
+
+	err := grammar.Balance()
+
+	test.EqualError(err, `3:5: unbalanced group end`)
+}
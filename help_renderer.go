@@ -0,0 +1,193 @@
+package docopt
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	HelpDefaultWidth = 80
+)
+
+// HelpRenderer renders help from the parsed options instead of printing
+// the doc verbatim: option names are aligned into a column, descriptions
+// are re-flowed to the width and defaults are moved to their ends. The
+// rest of the doc is kept as is.
+type HelpRenderer struct {
+	// Width is the maximum display width of lines. If it's zero, COLUMNS
+	// environment variable is used, or HelpDefaultWidth if it's not set.
+	Width int
+
+	// Verbatim makes renderer return the doc without changes.
+	Verbatim bool
+}
+
+func (renderer *HelpRenderer) Render(program *Program) string {
+	sections := MatcherSections.FindStringSubmatchIndex(program.Doc)
+	if renderer.Verbatim || sections == nil || sections[4] < 0 ||
+		len(program.Options) == 0 {
+		return program.Doc
+	}
+
+	end := sections[5]
+	if strings.HasSuffix(program.Doc[:end], "\n") {
+		end--
+	}
+
+	return program.Doc[:sections[4]] +
+		renderer.renderOptions(program.Options) +
+		program.Doc[end:]
+}
+
+func (renderer *HelpRenderer) renderOptions(options []Option) string {
+	var (
+		width     = renderer.getWidth()
+		signature = make([]string, len(options))
+		column    = 0
+	)
+
+	// Names which are too long don't widen the column and are followed by
+	// description on the next line instead.
+	for index, option := range options {
+		signature[index] = strings.Repeat(" ", option.Level) +
+			getOptionSignature(option)
+
+		size := getDisplayWidth(signature[index]) + 2
+		if size > column && size <= width/2 {
+			column = size
+		}
+	}
+
+	lines := []string{}
+
+	for index, option := range options {
+		var (
+			name        = signature[index]
+			description = []string{}
+		)
+
+		for _, words := range getHelpParagraphs(option) {
+			description = append(
+				description,
+				getWrappedLines(words, width-column)...,
+			)
+		}
+
+		if len(description) == 0 {
+			lines = append(lines, name)
+
+			continue
+		}
+
+		if getDisplayWidth(name)+2 > column {
+			lines = append(lines, name)
+		} else {
+			description[0] = name +
+				strings.Repeat(" ", column-getDisplayWidth(name)) +
+				description[0]
+			lines = append(lines, description[0])
+			description = description[1:]
+		}
+
+		for _, line := range description {
+			lines = append(lines, strings.Repeat(" ", column)+line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (renderer *HelpRenderer) getWidth() int {
+	if renderer.Width > 0 {
+		return renderer.Width
+	}
+
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err == nil && width > 0 {
+		return width
+	}
+
+	return HelpDefaultWidth
+}
+
+// getOptionSignature returns names of the option with its value after the
+// last name.
+func getOptionSignature(option Option) string {
+	signature := strings.Join(option.Names, ", ")
+
+	switch {
+	case !option.HasArgument():
+		return signature
+
+	case strings.HasPrefix(option.Names[len(option.Names)-1], "--"):
+		return signature + "=" + option.Value
+
+	default:
+		return signature + " " + option.Value
+	}
+}
+
+// getHelpParagraphs returns words of description paragraphs of the option
+// with default value moved to the end as a single word.
+func getHelpParagraphs(option Option) [][]string {
+	var (
+		description = option.GetDescription()
+		paragraphs  = [][]string{}
+	)
+
+	value, ok := option.GetDefault()
+	if ok {
+		description = MatcherDescriptionDefaultText.ReplaceAllString(
+			description,
+			"",
+		)
+	}
+
+	for _, paragraph := range strings.Split(description, "\n") {
+		if words := strings.Fields(paragraph); len(words) > 0 {
+			paragraphs = append(paragraphs, words)
+		}
+	}
+
+	if ok {
+		if len(paragraphs) == 0 {
+			paragraphs = append(paragraphs, []string{})
+		}
+
+		last := len(paragraphs) - 1
+
+		paragraphs[last] = append(paragraphs[last], "[default: "+value+"]")
+	}
+
+	return paragraphs
+}
+
+// getWrappedLines joins words into lines no wider than the given width,
+// words which are wider than width are kept whole.
+func getWrappedLines(words []string, width int) []string {
+	var (
+		lines = []string{}
+		line  string
+	)
+
+	for _, word := range words {
+		if line != "" && getDisplayWidth(line+" "+word) > width {
+			lines = append(lines, line)
+
+			line = ""
+		}
+
+		if line == "" {
+			line = word
+		} else {
+			line += " " + word
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
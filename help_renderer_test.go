@@ -0,0 +1,107 @@
+package docopt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHelpRendererDoc = `Naval Fate.
+
+Usage:
+  naval ship <name> move <x> <y> [--speed=<kn>]
+  naval --help
+
+Options:
+  -h --help     Show this screen.
+  --speed=<kn>  Speed in knots [default: 10]. Must not be greater
+                than the maximum speed of the ship.
+  -o, --output-directory=<path>  Where to save the log.
+  --quiet
+
+Sail safely.
+`
+
+func Test_HelpRenderer_AlignsAndWrapsOptions(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testHelpRendererDoc)
+	test.NoError(err)
+
+	test.Equal(`Naval Fate.
+
+Usage:
+  naval ship <name> move <x> <y> [--speed=<kn>]
+  naval --help
+
+Options:
+  -h, --help    Show this screen.
+  --speed=<kn>  Speed in knots. Must not be
+                greater than the maximum
+                speed of the ship.
+                [default: 10]
+  -o, --output-directory=<path>
+                Where to save the log.
+  --quiet
+
+Sail safely.
+`, (&HelpRenderer{Width: 44}).Render(program))
+}
+
+func Test_HelpRenderer_ReturnsDocVerbatim(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testHelpRendererDoc)
+	test.NoError(err)
+
+	test.Equal(
+		testHelpRendererDoc,
+		(&HelpRenderer{Width: 44, Verbatim: true}).Render(program),
+	)
+}
+
+func Test_HelpRenderer_TakesWidthFromColumns(t *testing.T) {
+	test := assert.New(t)
+
+	columns, ok := os.LookupEnv("COLUMNS")
+
+	defer func() {
+		if ok {
+			os.Setenv("COLUMNS", columns)
+		} else {
+			os.Unsetenv("COLUMNS")
+		}
+	}()
+
+	os.Setenv("COLUMNS", "120")
+	test.Equal(120, (&HelpRenderer{}).getWidth())
+	test.Equal(60, (&HelpRenderer{Width: 60}).getWidth())
+
+	os.Setenv("COLUMNS", "wide")
+	test.Equal(HelpDefaultWidth, (&HelpRenderer{}).getWidth())
+}
+
+func Test_Program_PrintsRenderedHelp(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	program.Help = true
+	program.HelpRenderer = &HelpRenderer{Width: 80}
+	program.Output = output
+	program.Exit = func(int) {}
+
+	_, err = program.Parse([]string{`--help`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Contains(output.String(), "  -h, --help    Show this screen.\n")
+	test.Contains(
+		output.String(),
+		"  --speed=<kn>  Speed in knots. [default: 10]\n",
+	)
+}
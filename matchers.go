@@ -25,6 +25,14 @@ var (
 		MatcherOptionNameSet,
 	)
 
+	MatcherOptionsEnd = NewMatcher(
+		`--$`,
+	)
+
+	MatcherShortOptionsGroup = NewMatcher(
+		`-([^-].*)`,
+	)
+
 	MatcherOption = NewMatcher(
 		`%[1]s(?:%[2]s?%[3]s)?`,
 		MatcherOptionName,
@@ -52,6 +60,10 @@ var (
 		`(?s)(?:.*)\[default: ([^\]]+)]`,
 	)
 
+	MatcherDescriptionDefaultText = NewMatcher(
+		`\s*\[default: [^\]]+]`,
+	)
+
 	MatcherTokenSeparator = NewMatcher(
 		`\s+`,
 	)
@@ -84,6 +96,10 @@ var (
 		`\.\.\.`,
 	)
 
+	MatcherTokenStdin = NewMatcher(
+		`-`,
+	)
+
 	MatcherTokenWord = NewMatcher(
 		`([^ \t([[\]()<>|.-]%[1]s*)`,
 		MatcherOptionNameSet,
@@ -0,0 +1,15 @@
+package docopt
+
+type Node interface {
+	GetChildren() []Node
+}
+
+func Walk(node Node, visitor func(node Node) bool) {
+	if !visitor(node) {
+		return
+	}
+
+	for _, child := range node.GetChildren() {
+		Walk(child, visitor)
+	}
+}
@@ -0,0 +1,9 @@
+package docopt
+
+type NodeArgument struct {
+	Name string
+}
+
+func (node *NodeArgument) GetChildren() []Node {
+	return nil
+}
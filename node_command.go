@@ -0,0 +1,9 @@
+package docopt
+
+type NodeCommand struct {
+	Name string
+}
+
+func (node *NodeCommand) GetChildren() []Node {
+	return nil
+}
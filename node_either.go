@@ -0,0 +1,9 @@
+package docopt
+
+type NodeEither struct {
+	Children []Node
+}
+
+func (node *NodeEither) GetChildren() []Node {
+	return node.Children
+}
@@ -0,0 +1,9 @@
+package docopt
+
+type NodeOneOrMore struct {
+	Children []Node
+}
+
+func (node *NodeOneOrMore) GetChildren() []Node {
+	return node.Children
+}
@@ -0,0 +1,10 @@
+package docopt
+
+type NodeOption struct {
+	Name  string
+	Value string
+}
+
+func (node *NodeOption) GetChildren() []Node {
+	return nil
+}
@@ -0,0 +1,9 @@
+package docopt
+
+type NodeOptional struct {
+	Children []Node
+}
+
+func (node *NodeOptional) GetChildren() []Node {
+	return node.Children
+}
@@ -0,0 +1,7 @@
+package docopt
+
+type NodeOptionsEnd struct{}
+
+func (node *NodeOptionsEnd) GetChildren() []Node {
+	return nil
+}
@@ -0,0 +1,9 @@
+package docopt
+
+type NodeOptionsShortcut struct {
+	Options []Option
+}
+
+func (node *NodeOptionsShortcut) GetChildren() []Node {
+	return nil
+}
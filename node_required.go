@@ -0,0 +1,9 @@
+package docopt
+
+type NodeRequired struct {
+	Children []Node
+}
+
+func (node *NodeRequired) GetChildren() []Node {
+	return node.Children
+}
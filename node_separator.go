@@ -0,0 +1,7 @@
+package docopt
+
+type NodeSeparator struct{}
+
+func (node *NodeSeparator) GetChildren() []Node {
+	return nil
+}
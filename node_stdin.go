@@ -0,0 +1,7 @@
+package docopt
+
+type NodeStdin struct{}
+
+func (node *NodeStdin) GetChildren() []Node {
+	return nil
+}
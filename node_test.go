@@ -0,0 +1,42 @@
+package docopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk_VisitsNodesInOrder(t *testing.T) {
+	test := assert.New(t)
+
+	tree := &NodeRequired{
+		Children: []Node{
+			&NodeCommand{Name: "ship"},
+			&NodeOptional{
+				Children: []Node{
+					&NodeOption{Name: "--speed", Value: "<kn>"},
+				},
+			},
+			&NodeArgument{Name: "<name>"},
+		},
+	}
+
+	names := []string{}
+
+	Walk(tree, func(node Node) bool {
+		switch node := node.(type) {
+		case *NodeCommand:
+			names = append(names, node.Name)
+		case *NodeOption:
+			names = append(names, node.Name)
+		case *NodeArgument:
+			names = append(names, node.Name)
+		case *NodeOptional:
+			return false
+		}
+
+		return true
+	})
+
+	test.Equal([]string{"ship", "<name>"}, names)
+}
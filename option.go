@@ -43,3 +43,13 @@ func (option *Option) GetDefault() (string, bool) {
 
 	return matches[1], true
 }
+
+func (option *Option) GetName() string {
+	for _, name := range option.Names {
+		if strings.HasPrefix(name, "--") {
+			return name
+		}
+	}
+
+	return option.Names[0]
+}
@@ -1,9 +1,12 @@
 package docopt
 
-type OptionsParser struct{}
+type OptionsParser struct {
+	// Offset is the number of doc lines preceding the options section.
+	Offset int
+}
 
 func (parser *OptionsParser) Parse(section string) ([]Option, error) {
-	scanner := NewScanner(section)
+	scanner := NewScannerAt(section, parser.Offset)
 
 	var (
 		option  *Option
@@ -0,0 +1,301 @@
+package docopt
+
+import (
+	"io"
+	"strings"
+)
+
+type Program struct {
+	Doc      string
+	Usage    *Usage
+	Options  []Option
+	Variants []Grammar
+
+	Abbreviate   bool
+	OptionsFirst bool
+
+	// Help makes Parse print the doc, or only its usage section if
+	// HelpUsageOnly is set, when --help or any of its aliases is given.
+	Help          bool
+	HelpUsageOnly bool
+
+	// HelpRenderer, if set, renders the doc printed on --help.
+	HelpRenderer *HelpRenderer
+
+	// Version is printed when --version is given, if not empty.
+	Version string
+
+	// Completers provide candidates for positional arguments and option
+	// values by their placeholder names, such as "<branch>". If any is set,
+	// Parse also answers hidden __complete requests, see Complete.
+	Completers map[string]Completer
+
+	// Output and Exit are used to print help or version and to exit
+	// afterwards. They default to os.Stdout and os.Exit.
+	Output io.Writer
+	Exit   func(code int)
+
+	// trees are built from Variants once by Compile and are only read
+	// afterwards, so program can be used concurrently.
+	trees []*NodeRequired
+}
+
+func Compile(doc string) (*Program, error) {
+	sections := MatcherSections.FindStringSubmatchIndex(doc)
+	if sections == nil {
+		return nil, ErrParseFailed{
+			Message: `"usage:" section not found`,
+		}
+	}
+
+	usage, err := (&UsageParser{
+		Offset: strings.Count(doc[:sections[2]], "\n"),
+	}).Parse(doc[sections[2]:sections[3]])
+	if err != nil {
+		return nil, err
+	}
+
+	var options []Option
+
+	if sections[4] >= 0 {
+		options, err = (&OptionsParser{
+			Offset: strings.Count(doc[:sections[4]], "\n"),
+		}).Parse(doc[sections[4]:sections[5]])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	program := &Program{
+		Doc:     doc,
+		Usage:   usage,
+		Options: options,
+
+		Abbreviate: true,
+	}
+
+	for _, variant := range usage.Variants {
+		variant.ResolveOptionsShortcuts(options)
+
+		begin, end := variant.getSpans()
+
+		grammar := Grammar{&TokenGroup{
+			TokenSource: TokenSource{Source: begin},
+			Opened:      true,
+			Required:    true,
+		}}
+		grammar = append(grammar, variant.clone()...)
+		grammar = append(grammar, &TokenGroup{
+			TokenSource: TokenSource{Source: end},
+			Required:    true,
+		})
+
+		err := grammar.Balance()
+		if err != nil {
+			return nil, getDocError(doc, err)
+		}
+
+		program.Variants = append(program.Variants, grammar)
+	}
+
+	program.trees, err = getTrees(program.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+func Parse(doc string, argv []string) (*Result, error) {
+	program, err := Compile(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return program.Parse(argv)
+}
+
+func (program *Program) Parse(argv []string) (*Result, error) {
+	if program.handleBuiltins(argv) {
+		return nil, ErrExited{Code: 0}
+	}
+
+	matcher := &ArgumentsMatcher{
+		Abbreviate:   program.Abbreviate,
+		OptionsFirst: program.OptionsFirst,
+	}
+
+	trees, err := program.getTrees()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := matcher.matchTrees(argv, trees, program.Options)
+
+	switch failed := err.(type) {
+	case ErrMissingArgument:
+		failed.Usage = program.GetUsage(argv)
+
+		return nil, failed
+
+	case ErrUnexpectedArgument:
+		failed.Usage = program.GetUsage(argv)
+
+		return nil, failed
+
+	case ErrNoVariantMatched:
+		failed.Usage = program.GetUsage(argv)
+
+		return nil, failed
+	}
+
+	return result, err
+}
+
+// getTrees returns trees built by Compile, or builds them if program is
+// constructed by hand.
+func (program *Program) getTrees() ([]*NodeRequired, error) {
+	if program.trees != nil {
+		return program.trees, nil
+	}
+
+	return getTrees(program.Variants)
+}
+
+// GetUsage returns usage lines of variants which start with the longest
+// sequence of commands given in argv, or all usage lines if argv doesn't
+// start with any known command.
+func (program *Program) GetUsage(argv []string) []string {
+	return program.getUsageLines(
+		program.GetCommandVariants(program.getCommandPrefix(argv)),
+	)
+}
+
+// GetCommandVariants returns variants which start with the given static
+// words.
+func (program *Program) GetCommandVariants(commands []string) []Grammar {
+	variants := []Grammar{}
+
+	for _, index := range program.getCommandIndexes(commands) {
+		variants = append(variants, program.Variants[index])
+	}
+
+	return variants
+}
+
+func (program *Program) getCommandIndexes(commands []string) []int {
+	indexes := []int{}
+
+	for index, variant := range program.Variants {
+		prefix := variant.getCommands()
+		if len(prefix) < len(commands) {
+			continue
+		}
+
+		matched := true
+
+		for position, command := range commands {
+			if prefix[position] != command {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return indexes
+}
+
+// getCommandPrefix returns the longest sequence of leading positional
+// words of argv which any variant starts with.
+func (program *Program) getCommandPrefix(argv []string) []string {
+	var (
+		words = []string{}
+		best  = 0
+	)
+
+	for _, arg := range argv {
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			words = append(words, arg)
+		}
+	}
+
+	for _, variant := range program.Variants {
+		var (
+			commands = variant.getCommands()
+			prefix   = 0
+		)
+
+		for prefix < len(commands) && prefix < len(words) &&
+			commands[prefix] == words[prefix] {
+			prefix++
+		}
+
+		if prefix > best {
+			best = prefix
+		}
+	}
+
+	return words[:best]
+}
+
+// getUsageLines returns lines of the doc which variants were parsed from.
+func (program *Program) getUsageLines(variants []Grammar) []string {
+	var (
+		lines = strings.Split(program.Doc, "\n")
+		usage = []string{}
+	)
+
+	for _, variant := range variants {
+		first, last := 0, 0
+
+		for _, token := range variant {
+			line := token.Span().Line
+			if line == 0 {
+				continue
+			}
+
+			if first == 0 || line < first {
+				first = line
+			}
+
+			if line > last {
+				last = line
+			}
+		}
+
+		for line := first; line > 0 && line <= last; line++ {
+			usage = append(usage, lines[line-1])
+		}
+	}
+
+	return usage
+}
+
+func getDocError(doc string, err error) error {
+	failed, ok := err.(ErrParseFailed)
+	if !ok || failed.Source.Line == 0 {
+		return err
+	}
+
+	lines := strings.Split(doc, "\n")
+	if failed.Source.Line > len(lines) {
+		return err
+	}
+
+	failed.Line = lines[failed.Source.Line-1]
+
+	if failed.Source.Start <= len(failed.Line) {
+		failed.Tail = failed.Line[failed.Source.Start:]
+	}
+
+	return failed
+}
@@ -0,0 +1,120 @@
+package docopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompleteCommand is the hidden command which generated completion scripts
+// use to request candidates from the binary when usage has completers:
+//
+//	prog __complete [<word>...] <current>
+//
+// Words are typed words after the binary name, the last one is the word
+// being completed and may be empty. Candidates are printed one per line as
+// value optionally followed by tab and description. Placeholders which have
+// no completers are not printed.
+//
+// Parse answers the request and exits if Completers are set.
+const CompleteCommand = "__complete"
+
+// Completer returns candidates for a placeholder given words typed before
+// the cursor and incomplete word at the cursor. Candidates are values to be
+// typed as is and are filtered by the prefix afterwards, so completer may
+// ignore it.
+type Completer func(argv []string, prefix string) []Candidate
+
+// Complete returns candidates which can be typed at the cursor, which is
+// index of the word being completed in argv. Words before the cursor are
+// matched against usage, missing required elements are not an error. Word
+// at the cursor may be incomplete and is used as prefix to filter static
+// words and options, while placeholders are returned unless they have
+// completers, in which case candidates of completers are returned instead.
+func (program *Program) Complete(argv []string, cursor int) []Candidate {
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	if cursor > len(argv) {
+		cursor = len(argv)
+	}
+
+	var prefix string
+
+	if cursor < len(argv) {
+		prefix = argv[cursor]
+	}
+
+	matcher := &ArgumentsMatcher{
+		Abbreviate:   program.Abbreviate,
+		OptionsFirst: program.OptionsFirst,
+	}
+
+	var (
+		candidates = []Candidate{}
+		completed  = map[string]bool{}
+	)
+
+	trees, err := program.getTrees()
+	if err != nil {
+		return candidates
+	}
+
+	for _, candidate := range matcher.completeTrees(
+		argv[:cursor],
+		trees,
+		program.Options,
+	) {
+		if candidate.Kind != CandidateKindArgument {
+			if strings.HasPrefix(candidate.Value, prefix) {
+				candidates = append(candidates, candidate)
+			}
+
+			continue
+		}
+
+		completer := program.Completers[candidate.Value]
+		if completer == nil {
+			candidates = append(candidates, candidate)
+
+			continue
+		}
+
+		if completed[candidate.Value] {
+			continue
+		}
+
+		completed[candidate.Value] = true
+
+		for _, dynamic := range completer(argv[:cursor], prefix) {
+			if strings.HasPrefix(dynamic.Value, prefix) {
+				candidates = append(candidates, dynamic)
+			}
+		}
+	}
+
+	return candidates
+}
+
+func (program *Program) isCompleteRequest(argv []string) bool {
+	return len(program.Completers) > 0 && len(argv) > 0 &&
+		argv[0] == CompleteCommand
+}
+
+// printCandidates answers __complete request, see CompleteCommand.
+func (program *Program) printCandidates(words []string) {
+	output := program.getOutput()
+
+	for _, candidate := range program.Complete(words, len(words)-1) {
+		if candidate.Kind == CandidateKindArgument {
+			continue
+		}
+
+		description := strings.Join(strings.Fields(candidate.Description), " ")
+		if description == "" {
+			fmt.Fprintln(output, candidate.Value)
+		} else {
+			fmt.Fprintf(output, "%s\t%s\n", candidate.Value, description)
+		}
+	}
+}
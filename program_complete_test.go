@@ -0,0 +1,205 @@
+package docopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Program_CompletesCommandsAndOptionsAtStart(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	test.Equal([]Candidate{
+		{Kind: CandidateKindWord, Value: `ship`},
+		{Kind: CandidateKindWord, Value: `mine`},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--speed`,
+			Description: `Speed in knots [default: 10].`,
+		},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--moored`,
+			Description: `Moored (anchored) mine.`,
+		},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--drifting`,
+			Description: `Drifting mine.`,
+		},
+		{Kind: CandidateKindOption, Value: `-h`, Description: `Show this screen.`},
+		{Kind: CandidateKindOption, Value: `--help`, Description: `Show this screen.`},
+		{Kind: CandidateKindOption, Value: `--version`, Description: `Show version.`},
+	}, program.Complete(nil, 0))
+}
+
+func Test_Program_CompletesIncompleteWord(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	test.Equal(
+		[]Candidate{{Kind: CandidateKindWord, Value: `ship`}},
+		program.Complete([]string{`sh`}, 0),
+	)
+
+	test.Equal([]Candidate{
+		{Kind: CandidateKindWord, Value: `move`},
+	}, program.Complete([]string{`ship`, `titanic`, `mo`, `10`}, 2))
+}
+
+func Test_Program_CompletesPlaceholdersWhenRequiredAreMissing(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	test.Equal([]Candidate{
+		{Kind: CandidateKindWord, Value: `new`},
+		{Kind: CandidateKindArgument, Value: `<name>`},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--speed`,
+			Description: `Speed in knots [default: 10].`,
+		},
+	}, program.Complete([]string{`ship`}, 1))
+
+	test.Equal([]Candidate{
+		{Kind: CandidateKindArgument, Value: `<y>`},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--moored`,
+			Description: `Moored (anchored) mine.`,
+		},
+	}, program.Complete([]string{`mine`, `set`, `1`, `--m`}, 3))
+}
+
+func Test_Program_CompletesOptionValue(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	test.Equal([]Candidate{
+		{
+			Kind:        CandidateKindArgument,
+			Value:       `<kn>`,
+			Description: `Speed in knots [default: 10].`,
+		},
+	}, program.Complete([]string{`ship`, `titanic`, `--speed`}, 3))
+}
+
+func Test_Program_DoesNotCompleteGivenOrUnknownOptions(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	test.Equal([]Candidate{
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--moored`,
+			Description: `Moored (anchored) mine.`,
+		},
+		{
+			Kind:        CandidateKindOption,
+			Value:       `--drifting`,
+			Description: `Drifting mine.`,
+		},
+	}, program.Complete([]string{`mine`, `set`, `1`, `2`}, 4))
+
+	test.Empty(program.Complete([]string{`mine`, `set`, `1`, `2`, `--moored`}, 5))
+
+	test.Empty(program.Complete([]string{`--moored`, `ship`, ``}, 2))
+	test.Empty(program.Complete([]string{`bogus`, ``}, 1))
+}
+
+func Test_Program_CompletesPlaceholdersWithCompleters(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  git checkout <branch> [--remote=<remote>]
+  git status
+
+Options:
+  --remote=<remote>  Remote to track.
+`)
+	test.NoError(err)
+
+	program.Completers = map[string]Completer{
+		`<branch>`: func(argv []string, prefix string) []Candidate {
+			return []Candidate{{Value: `master`}, {Value: `feature`}}
+		},
+		`<remote>`: func(argv []string, prefix string) []Candidate {
+			return []Candidate{{Value: `origin`}}
+		},
+	}
+
+	test.Equal([]Candidate{
+		{Value: `feature`},
+	}, program.Complete([]string{`checkout`, `f`}, 1))
+
+	test.Equal([]Candidate{
+		{Value: `origin`},
+	}, program.Complete([]string{`checkout`, `master`, `--remote`, ``}, 3))
+}
+
+func Test_Program_AnswersCompleteRequest(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  git checkout <branch> [--remote=<remote>]
+  git status
+
+Options:
+  --remote=<remote>  Remote to track.
+`)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Completers = map[string]Completer{
+		`<branch>`: func(argv []string, prefix string) []Candidate {
+			return []Candidate{
+				{Value: `master`, Description: "Main\n line."},
+				{Value: `feature`},
+			}
+		},
+	}
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	result, err := program.Parse([]string{CompleteCommand, `checkout`, ``})
+
+	test.Nil(result)
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal([]int{0}, codes)
+	test.Equal(
+		"master\tMain line.\nfeature\n--remote\tRemote to track.\n",
+		output.String(),
+	)
+}
+
+func Test_Program_IgnoresCompleteRequestWithoutCompleters(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  git checkout <branch>
+  git status
+`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{CompleteCommand, `checkout`, ``})
+
+	test.IsType(ErrNoVariantMatched{}, err)
+}
@@ -0,0 +1,267 @@
+package docopt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// GetUsageSection returns the "usage:" section of the doc including its
+// header.
+func (program *Program) GetUsageSection() string {
+	sections := MatcherSections.FindStringSubmatchIndex(program.Doc)
+	if sections == nil {
+		return ""
+	}
+
+	return strings.TrimRight(program.Doc[sections[0]:sections[3]], "\n")
+}
+
+// FormatError returns message of the error to be shown to user. Errors
+// returned by Parse which hold usage lines relevant to the typed command,
+// that is ErrMissingArgument, ErrUnexpectedArgument and ErrNoVariantMatched,
+// are followed by these lines under the usage header of the doc.
+func (program *Program) FormatError(err error) string {
+	usage, ok := err.(interface{ GetUsage() []string })
+	if !ok || len(usage.GetUsage()) == 0 {
+		return err.Error()
+	}
+
+	return err.Error() + "\n\n" + program.getUsageHeader() + "\n" +
+		strings.Join(usage.GetUsage(), "\n")
+}
+
+// GetCommandHelp returns help for the command given as leading static
+// words: usage lines of variants starting with them and options which these
+// variants reference. It returns empty string if there are no such
+// variants.
+func (program *Program) GetCommandHelp(commands []string) string {
+	variants := program.GetCommandVariants(commands)
+	if len(variants) == 0 {
+		return ""
+	}
+
+	var (
+		help    = program.getCommandUsage(variants)
+		options = program.getCommandOptions(
+			program.getCommandIndexes(commands),
+		)
+	)
+
+	if len(options) == 0 {
+		return help
+	}
+
+	renderer := program.HelpRenderer
+	if renderer == nil {
+		renderer = &HelpRenderer{}
+	}
+
+	header := "Options:"
+
+	sections := MatcherSections.FindStringSubmatchIndex(program.Doc)
+	if sections != nil && sections[4] >= 0 {
+		if text := strings.TrimSpace(
+			program.Doc[sections[3]:sections[4]],
+		); text != "" {
+			header = text
+		}
+	}
+
+	return help + "\n\n" + header + "\n" + renderer.renderOptions(options)
+}
+
+func (program *Program) getCommandUsage(variants []Grammar) string {
+	return program.getUsageHeader() + "\n" +
+		strings.Join(program.getUsageLines(variants), "\n")
+}
+
+func (program *Program) getUsageHeader() string {
+	sections := MatcherSections.FindStringSubmatchIndex(program.Doc)
+	if sections == nil {
+		return "Usage:"
+	}
+
+	return strings.TrimSpace(program.Doc[sections[0]:sections[2]])
+}
+
+// getCommandOptions returns options referenced by variants with the given
+// indexes in the order they are described in the doc.
+func (program *Program) getCommandOptions(indexes []int) []Option {
+	var (
+		trees      = []*NodeRequired{}
+		referenced = map[string]bool{}
+		options    = []Option{}
+	)
+
+	all, err := program.getTrees()
+	if err != nil {
+		return options
+	}
+
+	for _, index := range indexes {
+		trees = append(trees, all[index])
+	}
+
+	collected := (&ArgumentsMatcher{}).collectOptions(trees, program.Options)
+
+	for _, tree := range trees {
+		for _, name := range getOptionNames(tree, collected) {
+			referenced[name] = true
+		}
+	}
+
+	for _, option := range collected {
+		if referenced[option.GetName()] {
+			options = append(options, option)
+		}
+	}
+
+	return options
+}
+
+// getHelp returns help for the command which argv starts with, or the
+// whole doc if it doesn't start with any command.
+func (program *Program) getHelp(argv []string) string {
+	commands := program.getCommandPrefix(argv)
+
+	switch {
+	case len(commands) > 0 && program.HelpUsageOnly:
+		return program.getCommandUsage(program.GetCommandVariants(commands))
+
+	case len(commands) > 0:
+		return program.GetCommandHelp(commands)
+
+	case program.HelpUsageOnly:
+		return program.GetUsageSection()
+
+	case program.HelpRenderer != nil:
+		return program.HelpRenderer.Render(program)
+	}
+
+	return program.Doc
+}
+
+// isHelpCommand checks whether argv is "help [<command>...]" and usage
+// doesn't define help command by itself.
+func (program *Program) isHelpCommand(argv []string) bool {
+	if len(argv) == 0 || argv[0] != "help" {
+		return false
+	}
+
+	return len(program.GetCommandVariants([]string{"help"})) == 0
+}
+
+func (program *Program) handleBuiltins(argv []string) bool {
+	switch {
+	case program.isCompleteRequest(argv):
+		program.printCandidates(argv[1:])
+
+	case program.Help && program.isHelpCommand(argv):
+		program.print(program.getHelp(argv[1:]))
+
+	case program.Help && program.isGiven(argv, "--help"):
+		program.print(program.getHelp(argv))
+
+	case program.Version != "" && program.isGiven(argv, "--version"):
+		program.print(program.Version)
+
+	default:
+		return false
+	}
+
+	exit := program.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	exit(0)
+
+	return true
+}
+
+// isGiven checks whether any of names of the option with given name is
+// present in argv before full matching, so it works even if the rest of
+// arguments are invalid. Arguments are tokenized as for matching, so stacked
+// short options are found, while values of options and arguments after the
+// options end are not; unknown options and ambiguous groups are skipped.
+func (program *Program) isGiven(argv []string, name string) bool {
+	var (
+		options = program.Options
+		option  = findOption(options, name)
+		matcher = &ArgumentsMatcher{Abbreviate: program.Abbreviate}
+	)
+
+	if option == nil {
+		options = append(
+			append([]Option{}, options...),
+			Option{Names: []string{name}},
+		)
+
+		option = &options[len(options)-1]
+	}
+
+	parser := &ArgumentsParser{
+		Options:      options,
+		OptionsFirst: program.OptionsFirst,
+		Abbreviate:   program.Abbreviate,
+	}
+
+	args := append([]string{}, argv...)
+
+	for {
+		arguments, err := parser.Parse(args)
+		if input, ok := err.(ErrInput); ok {
+			args = append(args[:input.GetIndex()], args[input.GetIndex()+1:]...)
+
+			continue
+		}
+
+		if err != nil {
+			return false
+		}
+
+		value := false
+
+		for _, token := range arguments.Grammar {
+			switch token := token.(type) {
+			case *TokenOptionsEnd:
+				return false
+
+			case *TokenPositionalArgument:
+				if value {
+					value = false
+
+					continue
+				}
+
+				if program.OptionsFirst {
+					return false
+				}
+
+			case *TokenOption:
+				found, _ := matcher.findOption(options, token.Name)
+				if found != nil && found.GetName() == option.GetName() {
+					return true
+				}
+
+				value = found != nil && found.HasArgument() && token.Value == ""
+			}
+		}
+
+		return false
+	}
+}
+
+func (program *Program) print(text string) {
+	fmt.Fprintln(program.getOutput(), strings.TrimRight(text, "\n"))
+}
+
+func (program *Program) getOutput() io.Writer {
+	if program.Output != nil {
+		return program.Output
+	}
+
+	return os.Stdout
+}
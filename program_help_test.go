@@ -0,0 +1,302 @@
+package docopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Program_PrintsDocOnHelp(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	result, err := program.Parse([]string{`-h`, `bogus`, `--whatever`})
+
+	test.Nil(result)
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(testProgramDoc, output.String())
+	test.Equal([]int{0}, codes)
+}
+
+func Test_Program_PrintsOnlyUsageOnHelpIfRequested(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	program.Help = true
+	program.HelpUsageOnly = true
+	program.Output = output
+	program.Exit = func(int) {}
+
+	_, err = program.Parse([]string{`--he`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(`Usage:
+  naval ship new <name>
+  naval ship <name> move <x> <y> [--speed=<kn>]
+  naval mine (set|remove) <x> <y> [--moored|--drifting]
+  naval --help | --version
+`, output.String())
+}
+
+func Test_Program_PrintsVersion(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.Version = `Naval Fate 2.0`
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	_, err = program.Parse([]string{`--version`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal("Naval Fate 2.0\n", output.String())
+	test.Equal([]int{0}, codes)
+}
+
+func Test_Program_IgnoresHelpAfterOptionsEndOrWhenDisabled(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	_, err = program.Parse([]string{`ship`, `new`, `--`, `--help`})
+
+	test.IsType(ErrNoVariantMatched{}, err)
+
+	program.Help = false
+
+	result, err := program.Parse([]string{`--help`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--help`])
+	test.Empty(output.String())
+	test.Empty(codes)
+}
+
+func Test_Program_FindsHelpAmongStackedShortOptions(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  app [-v] [--data=<data>] <file>
+
+Options:
+  -h --help      Show help.
+  -v             Verbose.
+  --data=<data>  Data to send.
+`)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	_, err = program.Parse([]string{`-vh`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal([]int{0}, codes)
+
+	result, err := program.Parse([]string{`--data`, `--help`, `file`})
+
+	test.NoError(err)
+	test.Equal(`--help`, result.Values[`--data`])
+	test.Equal([]int{0}, codes)
+}
+
+func Test_Program_FindsHelpAfterOptionValueInOptionsFirstMode(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  git [-C <path>] <command> [<args>...]
+
+Options:
+  -h --help  Show help.
+  -C <path>  Run as if started in path.
+`)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.OptionsFirst = true
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	_, err = program.Parse([]string{`-C`, `dir`, `--help`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal([]int{0}, codes)
+
+	result, err := program.Parse([]string{`-C`, `dir`, `log`, `--help`})
+
+	test.NoError(err)
+	test.Equal([]string{`--help`}, result.Values[`<args>`])
+	test.Equal([]int{0}, codes)
+}
+
+func Test_Program_PrintsCommandHelp(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	var (
+		output = &bytes.Buffer{}
+		codes  = []int{}
+	)
+
+	program.Help = true
+	program.Output = output
+	program.Exit = func(code int) {
+		codes = append(codes, code)
+	}
+
+	_, err = program.Parse([]string{`ship`, `--help`, `titanic`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(`Usage:
+  naval ship new <name>
+  naval ship <name> move <x> <y> [--speed=<kn>]
+
+Options:
+  --speed=<kn>  Speed in knots. [default: 10]
+`, output.String())
+	test.Equal([]int{0}, codes)
+
+	output.Reset()
+
+	_, err = program.Parse([]string{`help`, `mine`, `set`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(`Usage:
+  naval mine (set|remove) <x> <y> [--moored|--drifting]
+
+Options:
+  --moored    Moored (anchored) mine.
+  --drifting  Drifting mine.
+`, output.String())
+}
+
+func Test_Program_PrintsDocOnHelpCommandWithoutKnownCommand(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	output := &bytes.Buffer{}
+
+	program.Help = true
+	program.HelpUsageOnly = true
+	program.Output = output
+	program.Exit = func(int) {}
+
+	_, err = program.Parse([]string{`help`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(program.GetUsageSection()+"\n", output.String())
+
+	output.Reset()
+
+	_, err = program.Parse([]string{`help`, `ship`})
+
+	test.Equal(ErrExited{Code: 0}, err)
+	test.Equal(`Usage:
+  naval ship new <name>
+  naval ship <name> move <x> <y> [--speed=<kn>]
+`, output.String())
+}
+
+func Test_Program_DoesNotHandleHelpCommandDefinedInUsage(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog help <topic>
+  prog remote add <name>
+`)
+	test.NoError(err)
+
+	program.Help = true
+
+	result, err := program.Parse([]string{`help`, `remote`})
+
+	test.NoError(err)
+	test.Equal(`remote`, result.Values[`<topic>`])
+}
+
+func Test_Program_GetsCommandVariants(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog remote add <name> [-f]
+  prog remote rm <name>
+  prog status [-s]
+
+Options:
+  -f  Fetch after adding.
+  -s  Short format.
+`)
+	test.NoError(err)
+
+	test.Len(program.GetCommandVariants([]string{`remote`}), 2)
+	test.Len(program.GetCommandVariants([]string{`remote`, `rm`}), 1)
+	test.Empty(program.GetCommandVariants([]string{`rm`}))
+	test.Len(program.GetCommandVariants(nil), 3)
+
+	test.Equal(`Usage:
+  prog remote add <name> [-f]
+  prog remote rm <name>
+
+Options:
+  -f  Fetch after adding.`, program.GetCommandHelp([]string{`remote`}))
+	test.Empty(program.GetCommandHelp([]string{`push`}))
+}
@@ -0,0 +1,600 @@
+package docopt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProgramDoc = `Naval Fate.
+
+Usage:
+  naval ship new <name>
+  naval ship <name> move <x> <y> [--speed=<kn>]
+  naval mine (set|remove) <x> <y> [--moored|--drifting]
+  naval --help | --version
+
+Options:
+  -h --help     Show this screen.
+  --version     Show version.
+  --speed=<kn>  Speed in knots [default: 10].
+  --moored      Moored (anchored) mine.
+  --drifting    Drifting mine.
+`
+
+func Test_Compile_ParsesUsageAndOptions(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+
+	test.NoError(err)
+	test.Equal(`naval`, program.Usage.Binary)
+	test.Len(program.Usage.Variants, 4)
+	test.Len(program.Options, 5)
+}
+
+func Test_Compile_ReturnsErrorWithoutUsageSection(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Just some text.`)
+
+	test.Nil(program)
+	test.Error(err)
+}
+
+func Test_Program_ParsesArgumentsSeveralTimes(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	result, err := program.Parse(
+		[]string{`ship`, `Guardian`, `move`, `10`, `50`, `--speed=20`},
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`ship`:       true,
+		`new`:        false,
+		`move`:       true,
+		`mine`:       false,
+		`set`:        false,
+		`remove`:     false,
+		`<name>`:     `Guardian`,
+		`<x>`:        `10`,
+		`<y>`:        `50`,
+		`--help`:     false,
+		`--version`:  false,
+		`--speed`:    `20`,
+		`--moored`:   false,
+		`--drifting`: false,
+	}, result.Values)
+
+	result, err = program.Parse([]string{`mine`, `set`, `1`, `2`, `--moored`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`set`])
+	test.Equal(true, result.Values[`--moored`])
+	test.Equal(`10`, result.Values[`--speed`])
+
+	result, err = program.Parse([]string{`-h`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--help`])
+}
+
+func Test_Parse_ReturnsErrorOnInvalidArguments(t *testing.T) {
+	test := assert.New(t)
+
+	result, err := Parse(testProgramDoc, []string{`ship`, `new`})
+
+	test.Nil(result)
+	test.IsType(ErrMissingArgument{}, err)
+
+	result, err = Parse(testProgramDoc, []string{`ship`})
+
+	test.Nil(result)
+	test.IsType(ErrNoVariantMatched{}, err)
+}
+
+func Test_Program_ResolvesAbbreviatedLongOptions(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	result, err := program.Parse([]string{`ship`, `x`, `move`, `1`, `2`, `--sp=5`})
+
+	test.NoError(err)
+	test.Equal(`5`, result.Values[`--speed`])
+
+	result, err = program.Parse([]string{`--ver`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--version`])
+
+	result, err = program.Parse([]string{`--he`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--help`])
+}
+
+func Test_Program_ReturnsErrorOnAmbiguousAbbreviation(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  blah [--verbose] [--version]
+`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`--ver`})
+
+	test.EqualError(err, `option --ver is ambiguous: --verbose, --version`)
+}
+
+func Test_Program_DisablesAbbreviations(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	program.Abbreviate = false
+
+	_, err = program.Parse([]string{`--vers`})
+
+	test.IsType(ErrUnknownOption{}, err)
+}
+
+func Test_Program_MatchesOptionsShortcut(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  blah [options] [-v] <file>
+
+Options:
+  -v --verbose  Be verbose.
+  -q --quiet    Be quiet.
+  -o <path>     Output path.
+`)
+	test.NoError(err)
+
+	result, err := program.Parse([]string{`-o`, `out`, `-v`, `in`, `-q`})
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--verbose`: true,
+		`--quiet`:   true,
+		`-o`:        `out`,
+		`<file>`:    `in`,
+	}, result.Values)
+
+	_, err = program.Parse([]string{`-v`, `-v`, `in`})
+
+	test.IsType(ErrUnexpectedArgument{}, err)
+}
+
+func Test_Program_MatchesOptionsEndAndStdin(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  blah [-v] [--] <file>
+  blah [-v] cat [-]
+`)
+	test.NoError(err)
+
+	result, err := program.Parse([]string{`-v`, `--`, `-x`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--`])
+	test.Equal(true, result.Values[`-v`])
+	test.Equal(`-x`, result.Values[`<file>`])
+
+	result, err = program.Parse([]string{`-`})
+
+	test.NoError(err)
+	test.Equal(false, result.Values[`--`])
+	test.Equal(`-`, result.Values[`<file>`])
+
+	result, err = program.Parse([]string{`cat`, `-`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`-`])
+
+	_, err = program.Parse([]string{`--`})
+
+	test.IsType(ErrMissingArgument{}, err)
+}
+
+func Test_Program_PassesArgumentsAfterCommandInOptionsFirstMode(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  git [--version] [-C <path>] <command> [<args>...]
+`)
+	test.NoError(err)
+
+	program.OptionsFirst = true
+
+	result, err := program.Parse(
+		[]string{`-C`, `repo`, `commit`, `-m`, `text`, `--`, `--version`},
+	)
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--version`: false,
+		`-C`:        `repo`,
+		`<command>`: `commit`,
+		`<args>`:    []string{`-m`, `text`, `--`, `--version`},
+	}, result.Values)
+
+	program.OptionsFirst = false
+
+	_, err = program.Parse([]string{`commit`, `-m`, `text`})
+
+	test.IsType(ErrUnknownOption{}, err)
+}
+
+func Test_Program_TakesValueOfAbbreviatedOptionInOptionsFirstMode(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog [--dat=<x>] [--verbose] <cmd> [<args>...]
+`)
+	test.NoError(err)
+
+	program.OptionsFirst = true
+
+	result, err := program.Parse([]string{`--da`, `val`, `--verbose`, `cmd`})
+
+	test.NoError(err)
+	test.EqualValues(map[string]interface{}{
+		`--dat`:     `val`,
+		`--verbose`: true,
+		`<cmd>`:     `cmd`,
+		`<args>`:    []string{},
+	}, result.Values)
+}
+
+func Test_Program_MatchesArgumentsWithLineBreaksAndEmptyArguments(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog [--msg=<msg>] <a> [<b>]
+`)
+	test.NoError(err)
+
+	result, err := program.Parse([]string{"x\ny"})
+
+	test.NoError(err)
+	test.Equal("x\ny", result.Values[`<a>`])
+	test.Nil(result.Values[`<b>`])
+
+	result, err = program.Parse([]string{"--msg=l1\nl2", `a`})
+
+	test.NoError(err)
+	test.Equal("l1\nl2", result.Values[`--msg`])
+	test.Equal(`a`, result.Values[`<a>`])
+	test.Nil(result.Values[`<b>`])
+
+	result, err = program.Parse([]string{``, `b`})
+
+	test.NoError(err)
+	test.Equal(``, result.Values[`<a>`])
+	test.Equal(`b`, result.Values[`<b>`])
+}
+
+func Test_Program_MatchesManyOptionalGroupsWithoutExpansion(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		usage = `Usage:
+  blah`
+		flags = []string{}
+	)
+
+	for i := 0; i < 30; i++ {
+		usage += fmt.Sprintf(` [--flag-%d]`, i)
+		flags = append(flags, fmt.Sprintf(`--flag-%d`, i))
+	}
+
+	program, err := Compile(usage + ` <file>`)
+	test.NoError(err)
+
+	result, err := program.Parse([]string{`--flag-29`, `x`, `--flag-3`})
+
+	test.NoError(err)
+	test.Equal(true, result.Values[`--flag-29`])
+	test.Equal(true, result.Values[`--flag-3`])
+	test.Equal(false, result.Values[`--flag-0`])
+	test.Equal(`x`, result.Values[`<file>`])
+
+	_, err = program.Parse([]string{`--flag-1`, `x`, `y`})
+
+	test.IsType(ErrUnexpectedArgument{}, err)
+
+	_, err = program.Parse(append(flags, `x`, `y`))
+
+	test.IsType(ErrUnexpectedArgument{}, err)
+}
+
+func Test_Program_ReportsDocPositionOfUnclosedGroup(t *testing.T) {
+	test := assert.New(t)
+
+	_, err := Compile("Tool.\n\nUsage:\n  prog go [<file>\n  prog stop\n")
+
+	test.IsType(ErrParseFailed{}, err)
+	test.EqualError(err, `4:11: group is not closed: "  prog go →[<file>"`)
+	test.Equal(
+		"4 |   prog go [<file>\n"+
+			"  |           ^",
+		err.(ErrParseFailed).Excerpt(),
+	)
+}
+
+func Test_Program_ReportsDocPositionOfStrayGroupEnd(t *testing.T) {
+	test := assert.New(t)
+
+	_, err := Compile("Usage:\n  prog <a>]\n")
+
+	test.EqualError(err, `2:11: unbalanced group end: "  prog <a>→]"`)
+
+	_, err = Compile("Usage:\n  prog <a>) <b>\n")
+
+	test.EqualError(err, `2:11: unbalanced group end: "  prog <a>→) <b>"`)
+}
+
+func Test_Program_ReportsDocPositionOfMismatchedGroup(t *testing.T) {
+	test := assert.New(t)
+
+	_, err := Compile("Usage:\n  prog go (<file>]\n")
+
+	test.EqualError(
+		err,
+		`2:18: "(" opened at 2:11 is closed by "]": "  prog go (<file>→]"`,
+	)
+}
+
+func Test_Program_ReportsDocPositionOfUsageSyntaxError(t *testing.T) {
+	test := assert.New(t)
+
+	_, err := Compile("Tool.\n\nUsage:\n  prog go\n  prog ( )\n")
+
+	test.IsType(ErrParseFailed{}, err)
+	test.Equal(5, err.(ErrParseFailed).Source.Line)
+}
+
+func Test_Program_SeparatesDocErrorsFromInputErrors(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		docErr   ErrDoc
+		inputErr ErrInput
+	)
+
+	_, err := Parse("Usage:\n  prog [<file>\n", []string{})
+
+	test.True(errors.As(err, &docErr))
+	test.False(errors.As(err, &inputErr))
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	for _, testcase := range []struct {
+		args []string
+		err  error
+	}{
+		{[]string{`ship`, `new`, `--bogus`}, ErrUnknownOption{
+			Index: 2,
+			Name:  `--bogus`,
+		}},
+		{[]string{`ship`, `new`, `x`, `--moored`}, ErrUnexpectedArgument{
+			Index:  3,
+			Option: findOption(program.Options, `--moored`),
+			Usage:  []string{`  naval ship new <name>`},
+		}},
+		{[]string{`ship`, `x`, `move`, `1`}, ErrMissingArgument{
+			Index:   4,
+			Name:    `<y>`,
+			Command: `ship move`,
+			Usage: []string{
+				`  naval ship new <name>`,
+				`  naval ship <name> move <x> <y> [--speed=<kn>]`,
+			},
+		}},
+		{[]string{`ship`, `x`, `move`, `1`, `2`, `--speed`}, ErrOptionRequiresValue{
+			Index:  5,
+			Name:   `--speed`,
+			Option: *findOption(program.Options, `--speed`),
+		}},
+	} {
+		_, err := program.Parse(testcase.args)
+
+		test.Equal(testcase.err, err)
+		test.True(errors.As(err, &inputErr))
+		test.False(errors.As(err, &docErr))
+	}
+}
+
+func Test_Program_SuggestsClosestNames(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`ship`, `new`, `x`, `--sped=10`})
+
+	test.EqualError(err, `unknown option --sped; did you mean --speed?`)
+
+	_, err = program.Parse([]string{`mien`, `set`, `1`, `2`})
+
+	test.Equal([]string{`mine`}, err.(ErrNoVariantMatched).Suggestions)
+
+	_, err = program.Parse([]string{`mine`, `ste`, `1`, `2`})
+
+	test.Equal(1, err.(ErrInput).GetIndex())
+	test.Equal([]string{`set`}, err.(ErrNoVariantMatched).Suggestions)
+}
+
+func Test_Program_ExplainsFailureRelativeToClosestVariant(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  app deploy <env> [--force]
+  app status [<service>]
+  app logs <service> <file>
+`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`deploy`, `--force`})
+
+	test.EqualError(err, `deploy: missing required <env>`)
+	test.Equal(
+		[]string{`  app deploy <env> [--force]`},
+		err.(ErrMissingArgument).Usage,
+	)
+
+	_, err = program.Parse([]string{`status`, `web`, `extra`})
+
+	test.EqualError(err, `unexpected argument "extra" after <service>`)
+	test.Equal(2, err.(ErrInput).GetIndex())
+
+	_, err = program.Parse([]string{`logs`, `web`})
+
+	test.EqualError(err, `logs: missing required <file>`)
+
+	_, err = program.Parse([]string{`restart`})
+
+	test.IsType(ErrNoVariantMatched{}, err)
+	test.Len(err.(ErrNoVariantMatched).Usage, 3)
+}
+
+func Test_Program_FormatsErrorWithRelevantUsage(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  app deploy <env> [--force]
+  app status [<service>]
+`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`deploy`, `--force`})
+
+	test.Equal(`deploy: missing required <env>
+
+Usage:
+  app deploy <env> [--force]`, program.FormatError(err))
+
+	_, err = program.Parse([]string{`status`, `--bogus`})
+
+	test.Equal(`unknown option --bogus`, program.FormatError(err))
+}
+
+func Test_Program_ParsesConcurrently(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(testProgramDoc)
+	test.NoError(err)
+
+	var (
+		group   sync.WaitGroup
+		results = make([]*Result, 8)
+	)
+
+	for index := range results {
+		group.Add(1)
+
+		go func(index int) {
+			defer group.Done()
+
+			results[index], _ = program.Parse(
+				[]string{`ship`, `Guardian`, `move`, `10`, `50`},
+			)
+		}(index)
+	}
+
+	group.Wait()
+
+	for _, result := range results {
+		test.NotNil(result)
+	}
+}
+
+func Test_Program_FailsOnRepeatedElementsWithoutBacktrackingBlowUp(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		flags = []string{}
+		words = []string{}
+	)
+
+	for i := 0; i < 12; i++ {
+		flags = append(flags, `-v`)
+	}
+
+	for i := 0; i < 16; i++ {
+		words = append(words, `x`)
+	}
+
+	program, err := Compile(`Usage:
+  prog [-v]... <x>`)
+	test.NoError(err)
+
+	_, err = program.Parse(flags)
+
+	test.IsType(ErrMissingArgument{}, err)
+
+	program, err = Compile(`Usage:
+  prog (-a | -b | -c)... <x>`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{
+		`-a`, `-b`, `-c`, `-a`, `-b`, `-c`,
+		`-a`, `-b`, `-c`, `-a`, `-b`, `-c`,
+	})
+
+	test.IsType(ErrMissingArgument{}, err)
+
+	program, err = Compile(`Usage:
+  prog ([<a>] [<b>])... end`)
+	test.NoError(err)
+
+	_, err = program.Parse(append(words, `stop`))
+
+	test.Error(err)
+}
+
+func Test_Program_SuggestsCommandForWordStartingWithIt(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog go`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`gox`})
+
+	test.IsType(ErrNoVariantMatched{}, err)
+	test.Equal([]string{`go`}, err.(ErrNoVariantMatched).Suggestions)
+}
+
+func Test_Program_ReportsMissingRequiredElementsOutsideOptionals(t *testing.T) {
+	test := assert.New(t)
+
+	program, err := Compile(`Usage:
+  prog deploy [<env>] --now`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`deploy`})
+
+	test.EqualError(err, `deploy: missing required --now`)
+
+	program, err = Compile(`Usage:
+  prog [<x>] [<y>] go`)
+	test.NoError(err)
+
+	_, err = program.Parse([]string{`a`})
+
+	test.EqualError(err, `missing required go`)
+}
@@ -0,0 +1,195 @@
+package docopt
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Result struct {
+	Values  map[string]interface{}
+	Options []Option
+}
+
+func (result *Result) Bool(key string) (bool, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return false, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return value, nil
+	case int:
+		return value > 0, nil
+	case string:
+		return strconv.ParseBool(value)
+	}
+
+	return false, result.errorf(key, value, "bool")
+}
+
+func (result *Result) String(key string) (string, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	}
+
+	return "", result.errorf(key, value, "string")
+}
+
+func (result *Result) Strings(key string) ([]string, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []string:
+		return value, nil
+	}
+
+	return nil, result.errorf(key, value, "list of strings")
+}
+
+func (result *Result) Int(key string) (int, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return value, nil
+	case string:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf(`%s: %q is not an integer`, key, value)
+		}
+
+		return number, nil
+	}
+
+	return 0, result.errorf(key, value, "integer")
+}
+
+func (result *Result) Float(key string) (float64, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf(`%s: %q is not a number`, key, value)
+		}
+
+		return number, nil
+	}
+
+	return 0, result.errorf(key, value, "number")
+}
+
+func (result *Result) Count(key string) (int, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+
+		return 0, nil
+	case int:
+		return value, nil
+	case string:
+		return 1, nil
+	case []string:
+		return len(value), nil
+	}
+
+	return 0, result.errorf(key, value, "count")
+}
+
+func (result *Result) Duration(key string) (time.Duration, error) {
+	value, err := result.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf(`%s: %q is not a duration`, key, value)
+		}
+
+		return duration, nil
+	}
+
+	return 0, result.errorf(key, value, "duration")
+}
+
+func (result *Result) Has(key string) (bool, error) {
+	count, err := result.Count(key)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// get returns value by key, option aliases are resolved to the name value
+// is stored under.
+func (result *Result) get(key string) (interface{}, error) {
+	if option := findOption(result.Options, key); option != nil {
+		key = option.GetName()
+	}
+
+	value, ok := result.Values[key]
+	if !ok {
+		return nil, fmt.Errorf(
+			`%q is not declared in usage or options`,
+			key,
+		)
+	}
+
+	return value, nil
+}
+
+func (result *Result) errorf(
+	key string,
+	value interface{},
+	expected string,
+) error {
+	return fmt.Errorf(
+		`%s: can't use %v (%T) as %s`,
+		key, value, value, expected,
+	)
+}
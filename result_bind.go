@@ -0,0 +1,259 @@
+package docopt
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BindTag = `docopt`
+)
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (result *Result) Bind(target interface{}) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Ptr || pointer.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf(`can't bind into %T: pointer to struct expected`, target)
+	}
+
+	var (
+		structure = pointer.Elem()
+		fields    = structure.Type()
+		keys      = result.getKeysByField()
+	)
+
+	for index := 0; index < fields.NumField(); index++ {
+		field := fields.Field(index)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		key, tagged := field.Tag.Lookup(BindTag)
+		if key == "-" {
+			continue
+		}
+
+		if !tagged {
+			key = keys[field.Name]
+			if key == "" {
+				continue
+			}
+		}
+
+		value, err := result.get(key)
+		if err != nil {
+			return err
+		}
+
+		err = bindValue(structure.Field(index), value)
+		if err != nil {
+			return fmt.Errorf(
+				`can't bind %s to field %s: %s`,
+				result.getDisplayName(key), field.Name, err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (result *Result) getKeysByField() map[string]string {
+	var (
+		keys   = []string{}
+		fields = map[string]string{}
+	)
+
+	for key := range result.Values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := getFieldName(key)
+
+		if _, ok := fields[name]; !ok {
+			fields[name] = key
+		}
+	}
+
+	return fields
+}
+
+func (result *Result) getDisplayName(key string) string {
+	if option := findOption(result.Options, key); option != nil {
+		return strings.Join(option.Names, ", ")
+	}
+
+	return key
+}
+
+func getFieldName(key string) string {
+	key = strings.Trim(key, "-<>")
+
+	if strings.ToUpper(key) == key {
+		key = strings.ToLower(key)
+	}
+
+	parts := strings.FieldsFunc(key, func(char rune) bool {
+		return char == '-' || char == '_' || char == ' '
+	})
+
+	for index, part := range parts {
+		parts[index] = strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+func bindValue(field reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(typeTextUnmarshaler) {
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf(`can't use %v as text`, value)
+		}
+
+		return field.Addr().Interface().(encoding.TextUnmarshaler).
+			UnmarshalText([]byte(text))
+	}
+
+	if field.Type() == typeDuration {
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf(`can't use %v as duration`, value)
+		}
+
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		var items []string
+
+		switch value := value.(type) {
+		case []string:
+			items = value
+		case string:
+			items = []string{value}
+		default:
+			return fmt.Errorf(`can't use %v as list`, value)
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+
+		for index, item := range items {
+			err := bindValue(slice.Index(index), item)
+			if err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+
+		return nil
+
+	case reflect.Bool:
+		switch value := value.(type) {
+		case bool:
+			field.SetBool(value)
+		case int:
+			field.SetBool(value > 0)
+		case string:
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+
+			field.SetBool(flag)
+		default:
+			return fmt.Errorf(`can't use %v as bool`, value)
+		}
+
+		return nil
+
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf(`can't use %v as string`, value)
+		}
+
+		field.SetString(text)
+
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		// Flag which is not repeated in usage is counted as 0 or 1, so
+		// count field doesn't depend on whether usage repeats the flag.
+		if flag, ok := value.(bool); ok {
+			value = 0
+
+			if flag {
+				value = 1
+			}
+		}
+	}
+
+	if count, ok := value.(int); ok {
+		value = strconv.Itoa(count)
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf(`can't use %v as %s`, value, field.Type())
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(text, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(number)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		number, err := strconv.ParseUint(text, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(number)
+
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(number)
+
+	default:
+		return fmt.Errorf(`unsupported field type %s`, field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package docopt
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Result_BindsValuesIntoStruct(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{
+		Values: map[string]interface{}{
+			`--dry-run`: true,
+			`--output`:  `out.txt`,
+			`--verbose`: 3,
+			`--ratio`:   `0.25`,
+			`--timeout`: `5s`,
+			`--port`:    []string{`80`, `443`},
+			`--listen`:  `127.0.0.1`,
+			`<file>`:    `in.txt`,
+			`deploy`:    true,
+		},
+	}
+
+	var config struct {
+		DryRun  bool
+		Target  string `docopt:"--output"`
+		Verbose int
+		Ratio   float64
+		Timeout time.Duration
+		Port    []uint16
+		Listen  net.IP
+		File    string
+		Deploy  bool
+		Skipped string `docopt:"-"`
+	}
+
+	err := result.Bind(&config)
+
+	test.NoError(err)
+	test.True(config.DryRun)
+	test.Equal(`out.txt`, config.Target)
+	test.Equal(3, config.Verbose)
+	test.Equal(0.25, config.Ratio)
+	test.Equal(5*time.Second, config.Timeout)
+	test.Equal([]uint16{80, 443}, config.Port)
+	test.Equal(`127.0.0.1`, config.Listen.String())
+	test.Equal(`in.txt`, config.File)
+	test.True(config.Deploy)
+}
+
+func Test_Result_ReturnsBindErrorWithOptionAndField(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{
+		Values: map[string]interface{}{
+			`--count`: `many`,
+		},
+		Options: []Option{
+			{Names: []string{`-c`, `--count`}, Value: `<n>`},
+		},
+	}
+
+	var config struct {
+		Count int
+	}
+
+	err := result.Bind(&config)
+
+	test.Error(err)
+	test.Contains(err.Error(), `-c, --count`)
+	test.Contains(err.Error(), `field Count`)
+}
+
+func Test_Result_BindsFlagIntoCountField(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{
+		Values: map[string]interface{}{
+			`--help`:    true,
+			`--verbose`: false,
+			`--quiet`:   2,
+		},
+	}
+
+	var config struct {
+		Help    int
+		Verbose uint
+		Quiet   int
+	}
+
+	test.NoError(result.Bind(&config))
+	test.Equal(1, config.Help)
+	test.Equal(uint(0), config.Verbose)
+	test.Equal(2, config.Quiet)
+}
+
+func Test_Result_ReturnsBindErrorOnUndeclaredTag(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{Values: map[string]interface{}{}}
+
+	var config struct {
+		Output string `docopt:"--outptu"`
+	}
+
+	test.Error(result.Bind(&config))
+	test.Error(result.Bind(config))
+}
+
+func Test_Result_BindsTagWithOptionAlias(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{
+		Values: map[string]interface{}{
+			`--output`: `out.txt`,
+		},
+		Options: []Option{
+			{Names: []string{`-o`, `--output`}, Value: `<file>`},
+		},
+	}
+
+	var config struct {
+		Output string `docopt:"-o"`
+	}
+
+	test.NoError(result.Bind(&config))
+	test.Equal(`out.txt`, config.Output)
+}
@@ -0,0 +1,118 @@
+package docopt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestResult() *Result {
+	return &Result{
+		Values: map[string]interface{}{
+			`--verbose`: 2,
+			`--force`:   true,
+			`--retries`: `3`,
+			`--ratio`:   `0.5`,
+			`--timeout`: `1m30s`,
+			`--output`:  nil,
+			`<file>`:    []string{`a`, `b`},
+			`deploy`:    false,
+		},
+	}
+}
+
+func Test_Result_ReturnsTypedValues(t *testing.T) {
+	test := assert.New(t)
+
+	result := getTestResult()
+
+	force, err := result.Bool(`--force`)
+	test.NoError(err)
+	test.True(force)
+
+	verbose, err := result.Count(`--verbose`)
+	test.NoError(err)
+	test.Equal(2, verbose)
+
+	retries, err := result.Int(`--retries`)
+	test.NoError(err)
+	test.Equal(3, retries)
+
+	ratio, err := result.Float(`--ratio`)
+	test.NoError(err)
+	test.Equal(0.5, ratio)
+
+	timeout, err := result.Duration(`--timeout`)
+	test.NoError(err)
+	test.Equal(90*time.Second, timeout)
+
+	output, err := result.String(`--output`)
+	test.NoError(err)
+	test.Equal(``, output)
+
+	files, err := result.Strings(`<file>`)
+	test.NoError(err)
+	test.Equal([]string{`a`, `b`}, files)
+
+	has, err := result.Has(`deploy`)
+	test.NoError(err)
+	test.False(has)
+
+	has, err = result.Has(`<file>`)
+	test.NoError(err)
+	test.True(has)
+}
+
+func Test_Result_ReturnsErrorOnUndeclaredKey(t *testing.T) {
+	test := assert.New(t)
+
+	result := getTestResult()
+
+	_, err := result.Bool(`--verbos`)
+	test.EqualError(err, `"--verbos" is not declared in usage or options`)
+
+	_, err = result.Has(`--verbos`)
+	test.Error(err)
+}
+
+func Test_Result_ResolvesOptionAliases(t *testing.T) {
+	test := assert.New(t)
+
+	result := &Result{
+		Values: map[string]interface{}{
+			`--verbose`: true,
+			`-o`:        `out.txt`,
+		},
+		Options: []Option{
+			{Names: []string{`-v`, `--verbose`}},
+			{Names: []string{`-o`}, Value: `<file>`},
+		},
+	}
+
+	verbose, err := result.Bool(`-v`)
+	test.NoError(err)
+	test.True(verbose)
+
+	output, err := result.String(`-o`)
+	test.NoError(err)
+	test.Equal(`out.txt`, output)
+
+	_, err = result.Bool(`-x`)
+	test.EqualError(err, `"-x" is not declared in usage or options`)
+}
+
+func Test_Result_ReturnsErrorOnTypeMismatch(t *testing.T) {
+	test := assert.New(t)
+
+	result := getTestResult()
+
+	_, err := result.String(`<file>`)
+	test.Error(err)
+
+	_, err = result.Int(`--ratio`)
+	test.Error(err)
+
+	_, err = result.Duration(`--retries`)
+	test.Error(err)
+}
@@ -11,6 +11,9 @@ type Scanner struct {
 
 	Line string
 	Tail string
+
+	Number  int
+	Matched Span
 }
 
 func NewScanner(input string) *Scanner {
@@ -19,11 +22,24 @@ func NewScanner(input string) *Scanner {
 	}
 }
 
+// NewScannerAt returns scanner for the input which starts after the given
+// number of lines of the enclosing text, so line numbers are reported
+// relative to that text.
+func NewScannerAt(input string, offset int) *Scanner {
+	scanner := NewScanner(input)
+
+	scanner.Number = offset
+
+	return scanner
+}
+
 func (scanner *Scanner) Scan() bool {
 	if scanner.Input.Scan() {
 		scanner.Line = scanner.Input.Text()
 		scanner.Tail = scanner.Line
 
+		scanner.Number++
+
 		return true
 	}
 
@@ -31,15 +47,38 @@ func (scanner *Scanner) Scan() bool {
 }
 
 func (scanner *Scanner) Match(matcher Matcher) (matches []string) {
+	start := scanner.Column()
+
 	matches, scanner.Tail = matcher.Match(scanner.Tail)
 
+	if matches != nil {
+		scanner.Matched = Span{
+			Line:  scanner.Number,
+			Start: start,
+			End:   scanner.Column(),
+		}
+	}
+
 	return matches
 }
 
+func (scanner *Scanner) Column() int {
+	return len(scanner.Line) - len(scanner.Tail)
+}
+
+func (scanner *Scanner) Source() TokenSource {
+	return TokenSource{Source: scanner.Matched}
+}
+
 func (scanner *Scanner) Errorf(message string, args ...interface{}) error {
 	return ErrParseFailed{
 		Message: fmt.Sprintf(message, args...),
 		Line:    scanner.Line,
 		Tail:    scanner.Tail,
+		Source: Span{
+			Line:  scanner.Number,
+			Start: scanner.Column(),
+			End:   scanner.Column(),
+		},
 	}
 }
@@ -0,0 +1,15 @@
+package docopt
+
+import "fmt"
+
+// Span points at characters of a single line: Line is 1-based, Start and End
+// are byte offsets of the first and past-the-last characters in that line.
+type Span struct {
+	Line  int
+	Start int
+	End   int
+}
+
+func (span Span) String() string {
+	return fmt.Sprintf("%d:%d", span.Line, span.Start+1)
+}
@@ -0,0 +1,125 @@
+package docopt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// getSuggestions returns candidates closest to the given name by edit
+// distance, ignoring ones which are too far to be a typo. One-letter short
+// options are never suggested because any of them is one edit away.
+func getSuggestions(name string, candidates []string) []string {
+	var (
+		suggestions []string
+		best        = -1
+		seen        = map[string]bool{}
+	)
+
+	for _, candidate := range candidates {
+		if seen[candidate] || candidate == name {
+			continue
+		}
+
+		seen[candidate] = true
+
+		if strings.HasPrefix(candidate, "-") &&
+			!strings.HasPrefix(candidate, "--") {
+			continue
+		}
+
+		distance := getEditDistance(name, candidate)
+
+		limit := utf8.RuneCountInString(candidate) / 3
+		if limit < 1 {
+			limit = 1
+		}
+
+		if distance > limit {
+			continue
+		}
+
+		switch {
+		case best < 0 || distance < best:
+			best = distance
+			suggestions = []string{candidate}
+
+		case distance == best:
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	sort.Strings(suggestions)
+
+	return suggestions
+}
+
+// getEditDistance returns optimal string alignment distance, which is
+// Levenshtein distance that also counts swap of adjacent characters as
+// a single edit.
+func getEditDistance(a, b string) int {
+	var (
+		source = []rune(a)
+		target = []rune(b)
+		rows   = make([][]int, len(source)+1)
+	)
+
+	for i := range rows {
+		rows[i] = make([]int, len(target)+1)
+		rows[i][0] = i
+	}
+
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			rows[i][j] = getMinimum(
+				rows[i-1][j]+1,
+				rows[i][j-1]+1,
+				rows[i-1][j-1]+cost,
+			)
+
+			if i > 1 && j > 1 &&
+				source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				rows[i][j] = getMinimum(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(source)][len(target)]
+}
+
+func getMinimum(values ...int) int {
+	minimum := values[0]
+
+	for _, value := range values[1:] {
+		if value < minimum {
+			minimum = value
+		}
+	}
+
+	return minimum
+}
+
+func formatSuggestions(suggestions []string) string {
+	switch len(suggestions) {
+	case 0:
+		return ""
+
+	case 1:
+		return fmt.Sprintf(`; did you mean %s?`, suggestions[0])
+	}
+
+	return fmt.Sprintf(
+		`; did you mean one of %s?`,
+		strings.Join(suggestions, ", "),
+	)
+}
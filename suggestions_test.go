@@ -0,0 +1,34 @@
+package docopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetEditDistance_CountsTranspositionAsSingleEdit(t *testing.T) {
+	test := assert.New(t)
+
+	test.Equal(0, getEditDistance(`status`, `status`))
+	test.Equal(1, getEditDistance(`stauts`, `status`))
+	test.Equal(1, getEditDistance(`--verbos`, `--verbose`))
+	test.Equal(3, getEditDistance(`kitten`, `sitting`))
+	test.Equal(2, getEditDistance(`工具`, `同步`))
+}
+
+func Test_GetSuggestions_ReturnsClosestCandidates(t *testing.T) {
+	test := assert.New(t)
+
+	test.Equal(
+		[]string{`--verbose`},
+		getSuggestions(`--verbos`, []string{`-v`, `--verbose`, `--version`}),
+	)
+
+	test.Equal(
+		[]string{`start`, `state`},
+		getSuggestions(`stat`, []string{`state`, `start`, `stop`, `status`}),
+	)
+
+	test.Nil(getSuggestions(`-x`, []string{`-v`, `-q`}))
+	test.Nil(getSuggestions(`deploy`, []string{`status`}))
+}
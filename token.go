@@ -1,3 +1,49 @@
 package docopt
 
-type Token interface{}
+type Token interface {
+	Kind() TokenKind
+	String() string
+	Span() Span
+}
+
+type TokenKind int
+
+const (
+	TokenKindSeparator TokenKind = iota
+	TokenKindStaticWord
+	TokenKindPositionalArgument
+	TokenKindOption
+	TokenKindGroup
+	TokenKindBranch
+	TokenKindRepeat
+	TokenKindOptionsShortcut
+	TokenKindOptionsEnd
+	TokenKindStdin
+)
+
+var tokenKindNames = map[TokenKind]string{
+	TokenKindSeparator:          "separator",
+	TokenKindStaticWord:         "static word",
+	TokenKindPositionalArgument: "positional argument",
+	TokenKindOption:             "option",
+	TokenKindGroup:              "group",
+	TokenKindBranch:             "branch",
+	TokenKindRepeat:             "repeat",
+	TokenKindOptionsShortcut:    "options shortcut",
+	TokenKindOptionsEnd:         "options end",
+	TokenKindStdin:              "stdin",
+}
+
+func (kind TokenKind) String() string {
+	return tokenKindNames[kind]
+}
+
+// TokenSource is embedded into every token and holds location of the token
+// in the usage section it was parsed from.
+type TokenSource struct {
+	Source Span
+}
+
+func (source *TokenSource) Span() Span {
+	return source.Source
+}
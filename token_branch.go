@@ -5,11 +5,17 @@ import (
 )
 
 type TokenBranch struct {
+	TokenSource
+
 	Start int
 	Next  int
 }
 
-func (branch TokenBranch) String() string {
+func (branch *TokenBranch) Kind() TokenKind {
+	return TokenKindBranch
+}
+
+func (branch *TokenBranch) String() string {
 	return fmt.Sprintf(
 		"|:%d,%d", branch.Start, branch.Next,
 	)
@@ -5,23 +5,32 @@ import (
 )
 
 type TokenGroup struct {
+	TokenSource
+
 	Opened   bool
 	Required bool
 
 	Pair int
 }
 
+func (group *TokenGroup) Kind() TokenKind {
+	return TokenKindGroup
+}
+
 func (group *TokenGroup) String() string {
+	return fmt.Sprintf("%s:%d", group.getBracket(), group.Pair)
+}
+
+func (group *TokenGroup) getBracket() string {
 	pairs := []string{"[", "]"}
 
 	if group.Required {
 		pairs = []string{"(", ")"}
 	}
 
-	pair := pairs[1]
 	if group.Opened {
-		pair = pairs[0]
+		return pairs[0]
 	}
 
-	return fmt.Sprintf("%s:%d", pair, group.Pair)
+	return pairs[1]
 }
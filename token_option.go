@@ -1,6 +1,20 @@
 package docopt
 
 type TokenOption struct {
-	Name        string
+	TokenSource
+
+	Name  string
 	Value string
 }
+
+func (option *TokenOption) Kind() TokenKind {
+	return TokenKindOption
+}
+
+func (option *TokenOption) String() string {
+	if option.Value == "" {
+		return option.Name
+	}
+
+	return option.Name + "=" + option.Value
+}
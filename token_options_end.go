@@ -0,0 +1,13 @@
+package docopt
+
+type TokenOptionsEnd struct {
+	TokenSource
+}
+
+func (end *TokenOptionsEnd) Kind() TokenKind {
+	return TokenKindOptionsEnd
+}
+
+func (end *TokenOptionsEnd) String() string {
+	return "--"
+}
@@ -0,0 +1,15 @@
+package docopt
+
+type TokenOptionsShortcut struct {
+	TokenSource
+
+	Options []Option
+}
+
+func (shortcut *TokenOptionsShortcut) Kind() TokenKind {
+	return TokenKindOptionsShortcut
+}
+
+func (shortcut *TokenOptionsShortcut) String() string {
+	return "options"
+}
@@ -1,5 +1,15 @@
 package docopt
 
 type TokenPositionalArgument struct {
+	TokenSource
+
 	Value string
 }
+
+func (argument *TokenPositionalArgument) Kind() TokenKind {
+	return TokenKindPositionalArgument
+}
+
+func (argument *TokenPositionalArgument) String() string {
+	return argument.Value
+}
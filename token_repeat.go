@@ -1,3 +1,13 @@
 package docopt
 
-type TokenRepeat struct{}
+type TokenRepeat struct {
+	TokenSource
+}
+
+func (repeat *TokenRepeat) Kind() TokenKind {
+	return TokenKindRepeat
+}
+
+func (repeat *TokenRepeat) String() string {
+	return "..."
+}
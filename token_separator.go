@@ -1,3 +1,13 @@
 package docopt
 
-type TokenSeparator struct{}
+type TokenSeparator struct {
+	TokenSource
+}
+
+func (separator *TokenSeparator) Kind() TokenKind {
+	return TokenKindSeparator
+}
+
+func (separator *TokenSeparator) String() string {
+	return " "
+}
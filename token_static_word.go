@@ -1,9 +1,15 @@
 package docopt
 
 type TokenStaticWord struct {
+	TokenSource
+
 	Name string
 }
 
+func (word *TokenStaticWord) Kind() TokenKind {
+	return TokenKindStaticWord
+}
+
 func (word *TokenStaticWord) String() string {
 	return word.Name
 }
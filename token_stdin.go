@@ -0,0 +1,13 @@
+package docopt
+
+type TokenStdin struct {
+	TokenSource
+}
+
+func (stdin *TokenStdin) Kind() TokenKind {
+	return TokenKindStdin
+}
+
+func (stdin *TokenStdin) String() string {
+	return "-"
+}
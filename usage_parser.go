@@ -2,10 +2,14 @@ package docopt
 
 import "io"
 
-type UsageParser struct{}
+type UsageParser struct {
+	// Offset is the number of doc lines preceding the section, so token
+	// spans and errors point at lines of the whole doc.
+	Offset int
+}
 
 func (parser *UsageParser) Parse(section string) (*Usage, error) {
-	scanner := NewScanner(section)
+	scanner := NewScannerAt(section, parser.Offset)
 
 	var (
 		usage   Usage
@@ -59,10 +63,20 @@ func (parser *UsageParser) parseBinaryName(
 		return nil, io.EOF
 	}
 
+	column := scanner.Column()
+
 	matches := scanner.Match(MatcherTokenWord)
 
 	tokens := []Token{
-		&TokenSeparator{},
+		&TokenSeparator{
+			TokenSource: TokenSource{
+				Source: Span{
+					Line:  scanner.Number,
+					Start: column,
+					End:   column,
+				},
+			},
+		},
 	}
 
 	if matches == nil {
@@ -81,7 +95,10 @@ func (parser *UsageParser) parseBinaryName(
 		return nil, nil
 	}
 
-	tokens = append(tokens, &TokenStaticWord{Name: matches[0]})
+	tokens = append(tokens, &TokenStaticWord{
+		TokenSource: scanner.Source(),
+		Name:        matches[0],
+	})
 
 	return tokens, nil
 }
@@ -91,21 +108,28 @@ func (parser *UsageParser) parseTokensGroupStart(
 ) ([]Token, error) {
 	tokens := []Token{}
 
-	if scanner.Match(MatcherTokenRequiredGroupStart) != nil {
-		tokens = append(tokens, &TokenGroup{
-			Opened:   true,
-			Required: true,
-		})
-	}
+	for {
+		switch {
+		case scanner.Match(MatcherTokenRequiredGroupStart) != nil:
+			tokens = append(tokens, &TokenGroup{
+				TokenSource: scanner.Source(),
+				Opened:      true,
+				Required:    true,
+			})
+
+		case scanner.Match(MatcherTokenOptionalGroupStart) != nil:
+			tokens = append(tokens, &TokenGroup{
+				TokenSource: scanner.Source(),
+				Opened:      true,
+				Required:    false,
+			})
+
+		default:
+			return tokens, nil
+		}
 
-	if scanner.Match(MatcherTokenOptionalGroupStart) != nil {
-		tokens = append(tokens, &TokenGroup{
-			Opened:   true,
-			Required: false,
-		})
+		scanner.Match(MatcherTokenSeparator)
 	}
-
-	return tokens, nil
 }
 
 func (parser *UsageParser) parseTokensGroupEnd(
@@ -113,17 +137,19 @@ func (parser *UsageParser) parseTokensGroupEnd(
 ) ([]Token, error) {
 	tokens := []Token{}
 
-	if scanner.Match(MatcherTokenRequiredGroupEnd) != nil {
+	switch {
+	case scanner.Match(MatcherTokenRequiredGroupEnd) != nil:
 		tokens = append(tokens, &TokenGroup{
-			Opened:   false,
-			Required: true,
+			TokenSource: scanner.Source(),
+			Opened:      false,
+			Required:    true,
 		})
-	}
 
-	if scanner.Match(MatcherTokenOptionalGroupEnd) != nil {
+	case scanner.Match(MatcherTokenOptionalGroupEnd) != nil:
 		tokens = append(tokens, &TokenGroup{
-			Opened:   false,
-			Required: false,
+			TokenSource: scanner.Source(),
+			Opened:      false,
+			Required:    false,
 		})
 	}
 
@@ -137,23 +163,38 @@ func (parser *UsageParser) parseTokensOptions(
 
 	matches := scanner.Match(MatcherOption)
 	if matches != nil {
-		tokens = append(tokens, &TokenOption{
-			Name:  matches[1],
-			Value: matches[2],
+		if matches[1] == "--" && matches[2] == "" {
+			tokens = append(tokens, &TokenOptionsEnd{
+				TokenSource: scanner.Source(),
+			})
+		} else {
+			tokens = append(tokens, &TokenOption{
+				TokenSource: scanner.Source(),
+				Name:        matches[1],
+				Value:       matches[2],
+			})
+		}
+	}
+
+	if len(tokens) == 0 && scanner.Match(MatcherTokenStdin) != nil {
+		tokens = append(tokens, &TokenStdin{
+			TokenSource: scanner.Source(),
 		})
 	}
 
 	matches = scanner.Match(MatcherArgument)
 	if matches != nil {
 		tokens = append(tokens, &TokenPositionalArgument{
-			Value: matches[1],
+			TokenSource: scanner.Source(),
+			Value:       matches[1],
 		})
 	}
 
 	matches = scanner.Match(MatcherTokenWord)
 	if matches != nil {
 		tokens = append(tokens, &TokenStaticWord{
-			Name: matches[0],
+			TokenSource: scanner.Source(),
+			Name:        matches[0],
 		})
 	}
 
@@ -166,7 +207,9 @@ func (parser *UsageParser) parseTokensRepeat(
 	tokens := []Token{}
 
 	if scanner.Match(MatcherTokenRepeat) != nil {
-		tokens = append(tokens, &TokenRepeat{})
+		tokens = append(tokens, &TokenRepeat{
+			TokenSource: scanner.Source(),
+		})
 	}
 
 	return tokens, nil
@@ -178,7 +221,9 @@ func (parser *UsageParser) parseTokensBranch(
 	tokens := []Token{}
 
 	if scanner.Match(MatcherTokenBranch) != nil {
-		tokens = append(tokens, &TokenBranch{})
+		tokens = append(tokens, &TokenBranch{
+			TokenSource: scanner.Source(),
+		})
 
 		scanner.Match(MatcherTokenSeparator)
 	}
@@ -186,11 +231,36 @@ func (parser *UsageParser) parseTokensBranch(
 	return tokens, nil
 }
 
+func (parser *UsageParser) isOptionsShortcut(
+	opened []Token,
+	options []Token,
+	closed []Token,
+) bool {
+	if len(opened) == 0 || len(options) != 1 || len(closed) == 0 {
+		return false
+	}
+
+	var (
+		start = opened[len(opened)-1].(*TokenGroup)
+		end   = closed[0].(*TokenGroup)
+	)
+
+	if start.Required || end.Required {
+		return false
+	}
+
+	word, ok := options[0].(*TokenStaticWord)
+
+	return ok && word.Name == `options`
+}
+
 func (parser *UsageParser) parseTokens(scanner *Scanner) ([]Token, error) {
 	tokens := []Token{}
 
 	if scanner.Match(MatcherTokenSeparator) != nil {
-		tokens = append(tokens, &TokenSeparator{})
+		tokens = append(tokens, &TokenSeparator{
+			TokenSource: scanner.Source(),
+		})
 	}
 
 	if scanner.Match(MatcherEndOfLine) != nil {
@@ -202,6 +272,8 @@ func (parser *UsageParser) parseTokens(scanner *Scanner) ([]Token, error) {
 		return nil, err
 	}
 
+	opened := groups
+
 	empty := false
 
 	if len(groups) > 0 {
@@ -212,7 +284,9 @@ func (parser *UsageParser) parseTokens(scanner *Scanner) ([]Token, error) {
 
 	if scanner.Match(MatcherTokenSeparator) != nil {
 		if !empty {
-			tokens = append(tokens, &TokenSeparator{})
+			tokens = append(tokens, &TokenSeparator{
+				TokenSource: scanner.Source(),
+			})
 		}
 	}
 
@@ -239,41 +313,52 @@ func (parser *UsageParser) parseTokens(scanner *Scanner) ([]Token, error) {
 		)
 	}
 
-	separator := false
-	if scanner.Match(MatcherTokenSeparator) != nil {
-		separator = true
-	}
+	atom := len(tokens) - 1
 
-	groups, err = parser.parseTokensGroupEnd(scanner)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(groups) > 0 {
+	var (
 		separator = false
-	}
+		spacing   Span
+		closed    = []Token{}
+	)
 
-	tokens = append(tokens, groups...)
+	for {
+		if scanner.Match(MatcherTokenSeparator) != nil {
+			separator = true
+			spacing = scanner.Matched
+		}
 
-	if len(tokens) == 0 {
-		return nil, scanner.Errorf(
-			`")" or "]" expected`,
-		)
-	}
+		groups, err := parser.parseTokensGroupEnd(scanner)
+		if err != nil {
+			return nil, err
+		}
 
-	repeat, err := parser.parseTokensRepeat(scanner)
-	if err != nil {
-		return nil, err
-	}
+		repeat, err := parser.parseTokensRepeat(scanner)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 && len(repeat) == 0 {
+			break
+		}
 
-	if len(repeat) > 0 {
 		separator = false
-	}
 
-	tokens = append(tokens, repeat...)
+		closed = append(closed, groups...)
 
-	if scanner.Match(MatcherTokenSeparator) != nil {
-		separator = true
+		tokens = append(tokens, groups...)
+		tokens = append(tokens, repeat...)
+	}
+
+	if parser.isOptionsShortcut(opened, options, closed) {
+		tokens[atom] = &TokenOptionsShortcut{
+			TokenSource: TokenSource{
+				Source: Span{
+					Line:  scanner.Number,
+					Start: opened[len(opened)-1].Span().Start,
+					End:   closed[0].Span().End,
+				},
+			},
+		}
 	}
 
 	branch, err := parser.parseTokensBranch(scanner)
@@ -295,7 +380,9 @@ func (parser *UsageParser) parseTokens(scanner *Scanner) ([]Token, error) {
 
 	if separator {
 		if scanner.Match(MatcherEndOfLine) == nil {
-			tokens = append(tokens, &TokenSeparator{})
+			tokens = append(tokens, &TokenSeparator{
+				TokenSource: TokenSource{Source: spacing},
+			})
 		}
 	}
 
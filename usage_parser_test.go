@@ -1,11 +1,28 @@
 package docopt
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func getUsageWithoutSpans(usage *Usage) *Usage {
+	if usage == nil {
+		return nil
+	}
+
+	for _, variant := range usage.Variants {
+		for _, token := range variant {
+			reflect.ValueOf(token).Elem().FieldByName(`TokenSource`).
+				Set(reflect.ValueOf(TokenSource{}))
+		}
+	}
+
+	return usage
+}
+
 func Test_UsageParser_ParsesEmptyUsage(t *testing.T) {
 	test := assert.New(t)
 
@@ -26,7 +43,7 @@ func Test_UsageParser_ParsesEmptyUsage(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -52,7 +69,7 @@ func Test_UsageParser_ParsesUsageWithSingleOption(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -83,7 +100,7 @@ func Test_UsageParser_ParsesUsageWithSingleBranch(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -113,7 +130,7 @@ func Test_UsageParser_ParsesUsageWithRepeat(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -146,7 +163,7 @@ func Test_UsageParser_ParsesUsageWithRequiredGroup(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -176,7 +193,7 @@ func Test_UsageParser_ParsesUsageWithStaticWord(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -208,7 +225,7 @@ func Test_UsageParser_ParsesUsageWithStaticWordsGroup(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -289,7 +306,7 @@ func Test_UsageParser_ParsesUsageWithSeveralUsages(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -318,7 +335,7 @@ func Test_UsageParser_ParsesMultilineUsage(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -368,7 +385,7 @@ func Test_UsageParser_ParsesMultilineUsageStartedWithStaticWord(t *testing.T) {
 		actual, err := parser.Parse(variant)
 
 		test.NoError(err)
-		test.EqualValues(expected, actual)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
 	}
 }
 
@@ -394,7 +411,7 @@ func Test_UsageParser_ParsesComplexArgumentPatterns(t *testing.T) {
 	actual, err := parser.Parse(section)
 
 	test.NoError(err)
-	test.EqualValues(expected, actual)
+	test.EqualValues(expected, getUsageWithoutSpans(actual))
 }
 
 func Test_UsageParser_ParsesComplexArgumentPatternsWithGroups(t *testing.T) {
@@ -422,5 +439,150 @@ func Test_UsageParser_ParsesComplexArgumentPatternsWithGroups(t *testing.T) {
 	actual, err := parser.Parse(section)
 
 	test.NoError(err)
-	test.EqualValues(expected, actual)
+	test.EqualValues(expected, getUsageWithoutSpans(actual))
+}
+
+func Test_UsageParser_ParsesOptionsShortcut(t *testing.T) {
+	test := assert.New(t)
+
+	variants := []string{
+		`blah [options] <file>`,
+		`blah [ options ] <file>`,
+	}
+
+	expected := &Usage{
+		Binary: "blah",
+		Variants: []Grammar{
+			{
+				&TokenGroup{Opened: true},
+				&TokenOptionsShortcut{},
+				&TokenGroup{Opened: false},
+				&TokenSeparator{},
+				&TokenPositionalArgument{Value: "<file>"},
+			},
+		},
+	}
+
+	parser := &UsageParser{}
+
+	for _, variant := range variants {
+		actual, err := parser.Parse(variant)
+
+		test.NoError(err)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
+	}
+}
+
+func Test_UsageParser_ParsesOptionsAsStaticWordOutsideShortcut(t *testing.T) {
+	test := assert.New(t)
+
+	expected := &Usage{
+		Binary: "blah",
+		Variants: []Grammar{
+			{
+				&TokenGroup{Opened: true, Required: true},
+				&TokenStaticWord{Name: "options"},
+				&TokenGroup{Opened: false, Required: true},
+			},
+		},
+	}
+
+	parser := &UsageParser{}
+
+	actual, err := parser.Parse(`blah (options)`)
+
+	test.NoError(err)
+	test.EqualValues(expected, getUsageWithoutSpans(actual))
+}
+
+func Test_UsageParser_ParsesOptionsEndAndStdin(t *testing.T) {
+	test := assert.New(t)
+
+	expected := &Usage{
+		Binary: "blah",
+		Variants: []Grammar{
+			{
+				&TokenGroup{Opened: true},
+				&TokenOptionsEnd{},
+				&TokenGroup{Opened: false},
+				&TokenSeparator{},
+				&TokenGroup{Opened: true, Required: true},
+				&TokenStdin{},
+				&TokenBranch{},
+				&TokenPositionalArgument{Value: "<file>"},
+				&TokenGroup{Opened: false, Required: true},
+			},
+		},
+	}
+
+	parser := &UsageParser{}
+
+	actual, err := parser.Parse(`blah [--] (- | <file>)`)
+
+	test.NoError(err)
+	test.EqualValues(expected, getUsageWithoutSpans(actual))
+}
+
+func Test_UsageParser_ParsesNestedGroupsWithRepeats(t *testing.T) {
+	test := assert.New(t)
+
+	variants := []string{
+		`blah [(-v | -q)...]`,
+		`blah [ ( -v | -q ) ... ]`,
+	}
+
+	expected := &Usage{
+		Binary: "blah",
+		Variants: []Grammar{
+			{
+				&TokenGroup{Opened: true},
+				&TokenGroup{Opened: true, Required: true},
+				&TokenOption{Name: "-v"},
+				&TokenBranch{},
+				&TokenOption{Name: "-q"},
+				&TokenGroup{Opened: false, Required: true},
+				&TokenRepeat{},
+				&TokenGroup{Opened: false},
+			},
+		},
+	}
+
+	parser := &UsageParser{}
+
+	for _, variant := range variants {
+		actual, err := parser.Parse(variant)
+
+		test.NoError(err)
+		test.EqualValues(expected, getUsageWithoutSpans(actual))
+	}
+}
+
+func Test_UsageParser_RecordsTokenSpans(t *testing.T) {
+	test := assert.New(t)
+
+	parser := &UsageParser{}
+
+	actual, err := parser.Parse("blah\n  blah [-v] <file>...")
+
+	test.NoError(err)
+
+	spans := []string{}
+
+	for _, token := range actual.Variants[1] {
+		spans = append(spans, fmt.Sprintf(
+			`%s %q %d-%d`,
+			token.Kind(), token.String(), token.Span().Start, token.Span().End,
+		))
+
+		test.Equal(2, token.Span().Line)
+	}
+
+	test.Equal([]string{
+		`group "[:0" 7-8`,
+		`option "-v" 8-10`,
+		`group "]:0" 10-11`,
+		`separator " " 11-12`,
+		`positional argument "<file>" 12-18`,
+		`repeat "..." 18-21`,
+	}, spans)
 }